@@ -1,35 +1,37 @@
 package app
 
 import (
+	"WbDemoProject/Internal/auth"
+	"WbDemoProject/Internal/cache"
 	"WbDemoProject/Internal/config"
+	"WbDemoProject/Internal/entity"
 	"WbDemoProject/Internal/handler"
 	"WbDemoProject/Internal/kafka"
+	"WbDemoProject/Internal/metrics"
 	"WbDemoProject/Internal/migrations"
+	"WbDemoProject/Internal/outbox"
+	"WbDemoProject/Internal/redaction"
 	"WbDemoProject/Internal/repository"
 	"WbDemoProject/Internal/usecase"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func Run() {
-	server := gin.Default()
-
-	// Добавляем CORS middleware чтобы фронт показывал json
-	server.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	})
+	server := gin.New()
+	server.Use(gin.Logger(), recoveryMiddleware())
+	server.Use(metrics.HTTPMiddleware())
 
 	//кфг
 	cfg, err := config.New()
@@ -37,62 +39,205 @@ func Run() {
 		log.Fatal(err)
 	}
 
+	apiKeys, err := auth.ParseKeys(cfg.APIKeys)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// CORS только для разрешенных источников, чтобы фронт показывал json
+	server.Use(corsMiddleware(cfg.CorsAllowedOrigins))
+
+	//ограниченный LRU кэш заказов
+	orderCache := cache.NewLRU(cache.Options[*entity.Order]{
+		MaxEntries: cfg.CacheMaxEntries,
+		MaxBytes:   cfg.CacheMaxBytes,
+		TTL:        cfg.CacheTTL,
+		SizeOf:     cache.OrderSize,
+	})
+
 	//репо с дб
 	repo, err := repository.New(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	//миграции
-	migration := migrations.New(repo)
-	err = migration.InitTables(context.Background())
+	//схема базы должна быть мигрирована заранее командой migrate up
+	migration, err := migrations.New(repo)
 	if err != nil {
-		log.Fatal("database dont created", err)
+		log.Fatal(err)
+	}
+
+	if err = migration.WaitForDB(context.Background(), dbWaitRetries, dbWaitDelay); err != nil {
+		log.Fatal(err)
+	}
+
+	if err = migration.CheckVersion(context.Background()); err != nil {
+		log.Fatal(err)
 	}
 
 	//бизнес логика
-	usecase := usecase.New(repo)
+	usecase := usecase.New(repo, orderCache)
 
-	//пример восстановления данных из бд в кэш
-	orders, err := usecase.GetAllOrdersFromDB(context.Background())
+	//прогреваем кэш самыми свежими заказами
+	loaded, err := usecase.WarmUpCache(context.Background(), cfg.CacheWarmupLimit)
 	if err != nil {
 		log.Fatal(err)
 	}
+	log.Printf("кэш прогрет, загружено заказов: %d", loaded)
 
-	for _, order := range orders {
-		err := usecase.SaveOrderInCache(order)
-		if err != nil {
-			log.Fatal(err)
-		}
-	}
+	//останавливаемся по SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	//инициализируем консюмера
-	ordersConsumer := kafka.New([]string{"kafka:9092"}, "orders", "1")
-	defer func(ordersConsumer *kafka.Consumer) {
-		err := ordersConsumer.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}(ordersConsumer)
+	ordersConsumer := kafka.New(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroupID, cfg.KafkaDLQTopic)
 
+	consumerDone := make(chan struct{})
 	go func() {
+		defer close(consumerDone)
+
 		for {
-			err := ordersConsumer.StartConsumer(context.Background(), usecase)
-			if err != nil {
-				log.Printf("Kafka not ready, retrying in 5s: %v", err)
-				time.Sleep(5 * time.Second)
+			err := ordersConsumer.StartConsumer(ctx, usecase)
+			if err == nil {
+				return
+			}
 
-				continue
+			log.Printf("Kafka not ready, retrying in 5s: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
 			}
-			break
 		}
 	}()
 
-	orderHandler := handler.New(usecase)
+	metrics.RegisterCache("orders", usecase.CacheStats)
+	metrics.RegisterCache("stats", usecase.StatsCacheStats)
 
-	server.GET("/order/:order_uid", orderHandler.GetOrder)
+	//публикуем события заказов из outbox
+	eventsPublisher := outbox.NewKafkaPublisher(cfg.KafkaBrokers, cfg.OutboxTopic)
+	relay := outbox.NewRelay(repo, eventsPublisher, outbox.Options{
+		PollInterval: cfg.OutboxPollInterval,
+		BatchSize:    cfg.OutboxBatchSize,
+	})
 
-	if err := server.Run(":8081"); err != nil {
-		log.Fatal(err)
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		relay.Run(ctx)
+	}()
+
+	// наружу заказы уходят только через политику роли запроса
+	orderHandler := handler.New(redaction.NewService(usecase, redaction.New(redaction.DefaultPolicies())), map[string]handler.Pinger{
+		"postgres": repo,
+		"kafka":    ordersConsumer,
+	})
+
+	server.GET("/healthz", orderHandler.Healthz)
+	server.GET("/readyz", orderHandler.Readyz)
+	server.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// данные заказов только по ключу API, поля скрываются по роли ключа
+	api := server.Group("/", auth.Middleware(apiKeys))
+	api.GET("/orders", orderHandler.ListOrders)
+	api.GET("/orders/export", orderHandler.ExportOrders)
+	api.GET("/order/:order_uid", orderHandler.GetOrder)
+	api.GET("/order/:order_uid/history", orderHandler.GetOrderHistory)
+	api.GET("/cache/stats", orderHandler.GetCacheStats)
+	api.GET("/stats", orderHandler.GetStats)
+	api.GET("/stats/:dimension", orderHandler.GetStatsBreakdown)
+
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.HTTPPort),
+		Handler: server,
+	}
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP server error: %v", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("завершаем работу сервиса")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	//дожидаемся обработки и коммита текущего сообщения
+	select {
+	case <-consumerDone:
+	case <-shutdownCtx.Done():
+		log.Println("консюмер не успел завершиться до дедлайна")
+	}
+
+	select {
+	case <-relayDone:
+	case <-shutdownCtx.Done():
+		log.Println("outbox relay не успел завершиться до дедлайна")
+	}
+
+	//дорабатываем текущие http запросы
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown error: %v", err)
+	}
+
+	if err := ordersConsumer.Close(); err != nil {
+		log.Printf("Kafka consumer close error: %v", err)
+	}
+
+	if err := eventsPublisher.Close(); err != nil {
+		log.Printf("outbox publisher close error: %v", err)
+	}
+
+	repo.Close()
+
+	log.Println("сервис остановлен")
+}
+
+// recoveryMiddleware - как gin.Recovery отвечаем 500 на панику, но http.ErrAbortHandler
+// пропускаем дальше: на нем net/http рвет соединение, а gin.Recovery завершил бы ответ как успешный
+func recoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			log.Printf("panic recovered: %v\n%s", err, debug.Stack())
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+
+		c.Next()
+	}
+}
+
+// corsMiddleware - отвечаем CORS заголовками только разрешенным источникам
+func corsMiddleware(allowedOrigins []string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.TrimSpace(origin)] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if _, ok := allowed[origin]; ok && origin != "" {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+			c.Header("Access-Control-Allow-Methods", "GET, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept-Encoding, Authorization, "+auth.APIKeyHeader)
+		}
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
 	}
 }
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"WbDemoProject/Internal/config"
+	"WbDemoProject/Internal/migrations"
+	"WbDemoProject/Internal/repository"
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// dbWaitRetries, dbWaitDelay - сколько ждем базу при старте
+const (
+	dbWaitRetries = 5
+	dbWaitDelay   = 5 * time.Second
+)
+
+// Migrate - подкоманда migrate: up (по умолчанию), down, status, redo
+func Migrate(ctx context.Context, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	cfg, err := config.New()
+	if err != nil {
+		return err
+	}
+
+	repo, err := repository.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	migration, err := migrations.New(repo)
+	if err != nil {
+		return err
+	}
+
+	if err = migration.WaitForDB(ctx, dbWaitRetries, dbWaitDelay); err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migration.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+
+	case "down", "redo":
+		action, run := "rolled back", migration.Down
+		if command == "redo" {
+			action, run = "redone", migration.Redo
+		}
+
+		m, err := run(ctx)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("no migrations applied")
+			return nil
+		}
+		fmt.Printf("%s %04d_%s\n", action, m.Version, m.Name)
+
+	case "status":
+		statuses, err := migration.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.DateTime)
+			}
+
+			name := s.Name
+			if s.Unknown {
+				name = "(unknown to this build)"
+			}
+
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, name, appliedAt)
+		}
+
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down, status or redo", command)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role - роль клиента API, от нее зависит какие поля заказа он видит
+type Role string
+
+const (
+	RoleAdmin   Role = "admin"
+	RoleSupport Role = "support"
+	RoleFinance Role = "finance"
+)
+
+// roleKey - ключ роли в gin.Context
+const roleKey = "auth_role"
+
+// roleContextKey - ключ роли в контексте http-запроса
+type roleContextKey struct{}
+
+// APIKeyHeader - заголовок с ключом API, также принимается Authorization: Bearer <key>
+const APIKeyHeader = "X-API-Key"
+
+// Keys - ключи API и их роли
+type Keys map[string]Role
+
+// ParseKeys - разбираем строку вида "key1:support,key2:finance"
+func ParseKeys(raw string) (Keys, error) {
+	keys := make(Keys)
+
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, role, ok := strings.Cut(pair, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("auth: invalid api key entry %q, expected key:role", pair)
+		}
+
+		switch r := Role(role); r {
+		case RoleAdmin, RoleSupport, RoleFinance:
+			keys[key] = r
+		default:
+			return nil, fmt.Errorf("auth: unknown role %q", role)
+		}
+	}
+
+	return keys, nil
+}
+
+// Middleware - пропускаем только запросы с известным ключом и запоминаем роль
+func Middleware(keys Keys) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(APIKeyHeader)
+		if key == "" {
+			key, _ = strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		}
+
+		role, ok := keys.lookup(key)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing API key"})
+			return
+		}
+
+		ctx.Set(roleKey, role)
+		ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), roleContextKey{}, role))
+		ctx.Next()
+	}
+}
+
+// RoleFrom - роль текущего запроса, выставленная Middleware
+func RoleFrom(ctx *gin.Context) (Role, bool) {
+	value, ok := ctx.Get(roleKey)
+	if !ok {
+		return "", false
+	}
+
+	role, ok := value.(Role)
+
+	return role, ok
+}
+
+// RoleFromContext - роль из контекста, который обработчик передал в сервис:
+// из самого *gin.Context или из контекста его запроса
+func RoleFromContext(ctx context.Context) (Role, bool) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		return RoleFrom(ginCtx)
+	}
+
+	role, ok := ctx.Value(roleContextKey{}).(Role)
+
+	return role, ok
+}
+
+// lookup - сравниваем ключи за постоянное время
+func (k Keys) lookup(key string) (Role, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	for known, role := range k {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(key)) == 1 {
+			return role, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache - интерфейс кэша, чтобы реализацию можно было подменить
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	Set(key string, value V)
+	Delete(key string)
+	Len() int
+	Stats() Stats
+}
+
+// Stats - счетчики кэша
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Expired   uint64 `json:"expired"`
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// Options - ограничения кэша, нулевое значение означает отсутствие ограничения
+type Options[V any] struct {
+	MaxEntries int
+	MaxBytes   int64
+	TTL        time.Duration
+	// SizeOf - оценка размера значения в байтах, нужна для MaxBytes
+	SizeOf func(V) int64
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	size      int64
+	expiresAt time.Time
+}
+
+// LRU - потокобезопасный кэш с вытеснением давно не используемых записей и TTL
+type LRU[V any] struct {
+	opts  Options[V]
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+	now   func() time.Time
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+	expired   atomic.Uint64
+}
+
+// NewLRU - конструктор LRU кэша
+func NewLRU[V any](opts Options[V]) *LRU[V] {
+	return &LRU[V]{
+		opts:  opts,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		now:   time.Now,
+	}
+}
+
+// Get - получаем значение и помечаем его как недавно использованное
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return zero, false
+	}
+
+	e := elem.Value.(*entry[V])
+	if !e.expiresAt.IsZero() && c.now().After(e.expiresAt) {
+		c.removeElement(elem)
+		c.expired.Add(1)
+		c.misses.Add(1)
+		return zero, false
+	}
+
+	c.ll.MoveToFront(elem)
+	c.hits.Add(1)
+
+	return e.value, true
+}
+
+// Set - добавляем или обновляем значение, при переполнении вытесняем старые записи
+func (c *LRU[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var size int64
+	if c.opts.SizeOf != nil {
+		size = c.opts.SizeOf(value)
+	}
+
+	var expiresAt time.Time
+	if c.opts.TTL > 0 {
+		expiresAt = c.now().Add(c.opts.TTL)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[V])
+		c.bytes += size - e.size
+		e.value, e.size, e.expiresAt = value, size, expiresAt
+		c.ll.MoveToFront(elem)
+	} else {
+		c.items[key] = c.ll.PushFront(&entry[V]{key: key, value: value, size: size, expiresAt: expiresAt})
+		c.bytes += size
+	}
+
+	// вытесняем с конца списка, но новую запись оставляем всегда
+	for c.ll.Len() > 1 && c.overflow() {
+		c.removeElement(c.ll.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Warm - добавляем запись в конец очереди как самую давно использованную, но только
+// если она помещается без вытеснения. false - места нет, запись не добавлена.
+// Прогрев от свежих к старым так оставляет свежие записи вытесняемыми последними
+func (c *LRU[V]) Warm(key string, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[key]; ok {
+		return true
+	}
+
+	var size int64
+	if c.opts.SizeOf != nil {
+		size = c.opts.SizeOf(value)
+	}
+
+	if c.opts.MaxEntries > 0 && c.ll.Len() >= c.opts.MaxEntries {
+		return false
+	}
+	if c.opts.MaxBytes > 0 && c.bytes+size > c.opts.MaxBytes {
+		return false
+	}
+
+	var expiresAt time.Time
+	if c.opts.TTL > 0 {
+		expiresAt = c.now().Add(c.opts.TTL)
+	}
+
+	c.items[key] = c.ll.PushBack(&entry[V]{key: key, value: value, size: size, expiresAt: expiresAt})
+	c.bytes += size
+
+	return true
+}
+
+// Delete - удаляем значение
+func (c *LRU[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Len - количество записей
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// Stats - текущие счетчики
+func (c *LRU[V]) Stats() Stats {
+	c.mu.Lock()
+	entries, bytes := c.ll.Len(), c.bytes
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Expired:   c.expired.Load(),
+		Entries:   entries,
+		Bytes:     bytes,
+	}
+}
+
+func (c *LRU[V]) overflow() bool {
+	if c.opts.MaxEntries > 0 && c.ll.Len() > c.opts.MaxEntries {
+		return true
+	}
+
+	return c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes
+}
+
+func (c *LRU[V]) removeElement(elem *list.Element) {
+	e := c.ll.Remove(elem).(*entry[V])
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(Options[int]{MaxEntries: 2})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("b must be evicted as least recently used")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("%s must stay in cache", key)
+		}
+	}
+
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("stats = %+v, want 1 eviction and 2 entries", stats)
+	}
+}
+
+func TestLRUWarmAddsColdWithoutEvicting(t *testing.T) {
+	c := NewLRU(Options[int]{MaxEntries: 2})
+
+	// прогрев от свежих к старым: a свежее b
+	if !c.Warm("a", 1) || !c.Warm("b", 2) {
+		t.Fatal("Warm() = false while cache has room")
+	}
+	if c.Warm("c", 3) {
+		t.Error("Warm() = true on full cache")
+	}
+	if stats := c.Stats(); stats.Evictions != 0 || stats.Entries != 2 {
+		t.Errorf("stats = %+v, want no evictions and 2 entries", stats)
+	}
+
+	// новая запись вытесняет самую старую из прогретых
+	c.Set("d", 4)
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("b must be evicted first as the oldest warmed entry")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("a must stay in cache")
+	}
+}
+
+func TestLRULimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options[string]
+		advance  time.Duration
+		wantKeys map[string]bool
+	}{
+		{
+			name:     "max bytes",
+			opts:     Options[string]{MaxBytes: 8, SizeOf: func(v string) int64 { return int64(len(v)) }},
+			wantKeys: map[string]bool{"a": false, "b": true, "c": true},
+		},
+		{
+			name:     "ttl expired",
+			opts:     Options[string]{TTL: time.Minute},
+			advance:  2 * time.Minute,
+			wantKeys: map[string]bool{"a": false, "b": false, "c": false},
+		},
+		{
+			name:     "ttl not expired",
+			opts:     Options[string]{TTL: time.Minute},
+			advance:  30 * time.Second,
+			wantKeys: map[string]bool{"a": true, "b": true, "c": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			c := NewLRU(tt.opts)
+			c.now = func() time.Time { return now }
+
+			c.Set("a", "aaaa")
+			c.Set("b", "bbbb")
+			c.Set("c", "cccc")
+
+			now = now.Add(tt.advance)
+
+			for key, want := range tt.wantKeys {
+				if _, ok := c.Get(key); ok != want {
+					t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+				}
+			}
+		})
+	}
+}
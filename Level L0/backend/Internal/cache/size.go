@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"WbDemoProject/Internal/entity"
+	"unsafe"
+)
+
+// OrderSize - примерный размер заказа в памяти, используется для ограничения кэша по байтам
+func OrderSize(order *entity.Order) int64 {
+	if order == nil {
+		return 0
+	}
+
+	size := int64(unsafe.Sizeof(*order)) +
+		int64(len(order.OrderUID)+len(order.TrackNumber)+len(order.Entry)+len(order.Locale)+
+			len(order.InternalSignature)+len(order.CustomerID)+len(order.DeliveryService)+
+			len(order.ShardKey)+len(order.OofShard))
+
+	d := order.Delivery
+	size += int64(len(d.Name) + len(d.Phone) + len(d.Zip) + len(d.City) + len(d.Address) + len(d.Region) + len(d.Email))
+
+	p := order.Payment
+	size += int64(len(p.Transaction) + len(p.RequestID) + len(p.Currency) + len(p.Provider) + len(p.Bank))
+
+	size += int64(cap(order.Items)) * int64(unsafe.Sizeof(entity.Item{}))
+	for _, item := range order.Items {
+		size += int64(len(item.TrackNumber) + len(item.Rid) + len(item.Name) + len(item.Size) + len(item.Brand))
+	}
+
+	return size
+}
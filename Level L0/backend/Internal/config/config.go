@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +16,26 @@ type Config struct {
 	DbPassword string `env:"DB_PASSWORD"`
 	DbHost     string `env:"DB_HOST"`
 	DbPort     int    `env:"DB_PORT"`
+
+	KafkaBrokers  []string `env:"KAFKA_BROKERS"`
+	KafkaTopic    string   `env:"KAFKA_TOPIC"`
+	KafkaGroupID  string   `env:"KAFKA_GROUP_ID"`
+	KafkaDLQTopic string   `env:"KAFKA_DLQ_TOPIC"`
+
+	OutboxTopic        string        `env:"OUTBOX_TOPIC"`
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE"`
+
+	HTTPPort        int           `env:"HTTP_PORT"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT"`
+
+	APIKeys            string   `env:"API_KEYS"`
+	CorsAllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS"`
+
+	CacheMaxEntries  int           `env:"CACHE_MAX_ENTRIES"`
+	CacheMaxBytes    int64         `env:"CACHE_MAX_BYTES"`
+	CacheTTL         time.Duration `env:"CACHE_TTL"`
+	CacheWarmupLimit int           `env:"CACHE_WARMUP_LIMIT"`
 }
 
 func New() (*Config, error) {
@@ -29,10 +51,106 @@ func New() (*Config, error) {
 	conf.DbPassword = os.Getenv("DB_PASSWORD")
 	conf.DbHost = os.Getenv("DB_HOST")
 
+	conf.KafkaBrokers = strings.Split(stringFromEnv("KAFKA_BROKERS", "kafka:9092"), ",")
+	conf.KafkaTopic = stringFromEnv("KAFKA_TOPIC", "orders")
+	conf.KafkaGroupID = stringFromEnv("KAFKA_GROUP_ID", "1")
+	conf.KafkaDLQTopic = stringFromEnv("KAFKA_DLQ_TOPIC", "orders-dlq")
+
 	conf.DbPort, err = strconv.Atoi(os.Getenv("DB_PORT"))
 	if err != nil {
 		return nil, fmt.Errorf("config UserService: error converting DB_PORT to int: %w", err)
 	}
 
+	conf.OutboxTopic = stringFromEnv("OUTBOX_TOPIC", "order-events")
+
+	conf.OutboxPollInterval, err = durationFromEnv("OUTBOX_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.OutboxBatchSize, err = intFromEnv("OUTBOX_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.APIKeys = os.Getenv("API_KEYS")
+	if conf.APIKeys == "" {
+		return nil, fmt.Errorf("config UserService: API_KEYS is required")
+	}
+
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		conf.CorsAllowedOrigins = strings.Split(origins, ",")
+	}
+
+	conf.HTTPPort, err = intFromEnv("HTTP_PORT", 8081)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.ShutdownTimeout, err = durationFromEnv("SHUTDOWN_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.CacheMaxEntries, err = intFromEnv("CACHE_MAX_ENTRIES", 10000)
+	if err != nil {
+		return nil, err
+	}
+
+	maxBytes, err := intFromEnv("CACHE_MAX_BYTES", 0)
+	if err != nil {
+		return nil, err
+	}
+	conf.CacheMaxBytes = int64(maxBytes)
+
+	conf.CacheTTL, err = durationFromEnv("CACHE_TTL", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.CacheWarmupLimit, err = intFromEnv("CACHE_WARMUP_LIMIT", 1000)
+	if err != nil {
+		return nil, err
+	}
+
 	return &conf, nil
 }
+
+// stringFromEnv - читаем строку из окружения, если переменной нет - значение по умолчанию
+func stringFromEnv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return def
+}
+
+// intFromEnv - читаем число из окружения, если переменной нет - значение по умолчанию
+func intFromEnv(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("config UserService: error converting %s to int: %w", key, err)
+	}
+
+	return n, nil
+}
+
+// durationFromEnv - читаем длительность (например 10m) из окружения
+func durationFromEnv(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("config UserService: error parsing %s: %w", key, err)
+	}
+
+	return d, nil
+}
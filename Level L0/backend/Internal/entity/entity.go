@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 type Order struct {
 	OrderUID          string    `json:"order_uid"`
@@ -55,3 +59,166 @@ type Item struct {
 	Brand       string `json:"brand"`
 	Status      int    `json:"status"`
 }
+
+// SaveStatus - результат сохранения заказа в БД
+type SaveStatus int
+
+const (
+	// OrderCreated - заказ сохранен впервые
+	OrderCreated SaveStatus = iota + 1
+	// OrderUpdated - пришел измененный заказ, сохранена новая версия
+	OrderUpdated
+	// OrderUnchanged - повторная доставка того же заказа, ничего не изменилось
+	OrderUnchanged
+)
+
+func (s SaveStatus) String() string {
+	switch s {
+	case OrderCreated:
+		return "created"
+	case OrderUpdated:
+		return "updated"
+	case OrderUnchanged:
+		return "unchanged"
+	default:
+		return "unknown"
+	}
+}
+
+// OrderRevision - версия заказа из истории изменений
+type OrderRevision struct {
+	Version     int       `json:"version"`
+	PayloadHash string    `json:"payload_hash"`
+	CreatedAt   time.Time `json:"created_at"`
+	Order       Order     `json:"order"`
+}
+
+// ErrOrderNotFound - заказа нет в хранилище
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrInvalidCursor - курсор пагинации не удалось разобрать
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// OrderFilter - фильтры и пагинация для списка заказов
+type OrderFilter struct {
+	CustomerID      string
+	DateFrom        *time.Time
+	DateTo          *time.Time
+	DeliveryService string
+	Provider        string
+	Currency        string
+	Brand           string
+	NmID            int
+	Query           string
+	Cursor          string
+	Limit           int
+}
+
+// OrderPage - страница списка заказов
+type OrderPage struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// типы событий заказа
+const (
+	EventOrderCreated = "order.created"
+	EventOrderUpdated = "order.updated"
+)
+
+// OrderEvent - событие из outbox, ожидающее публикации
+type OrderEvent struct {
+	ID        int64
+	Type      string
+	OrderUID  string
+	Payload   []byte
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// OrderEventPayload - тело события, которое получают внешние сервисы
+type OrderEventPayload struct {
+	Type       string          `json:"type"`
+	OrderUID   string          `json:"order_uid"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Order      json.RawMessage `json:"order"`
+}
+
+// StatsDimension - разрез статистики заказов
+type StatsDimension string
+
+const (
+	StatsByDay             StatsDimension = "day"
+	StatsByDeliveryService StatsDimension = "delivery_service"
+	StatsByProvider        StatsDimension = "provider"
+	StatsByCurrency        StatsDimension = "currency"
+	StatsByBrand           StatsDimension = "brand"
+)
+
+// StatsDimensions - все поддерживаемые разрезы
+var StatsDimensions = []StatsDimension{StatsByDay, StatsByDeliveryService, StatsByProvider, StatsByCurrency, StatsByBrand}
+
+// StatsFilter - период статистики [From, To), пустая граница не ограничивает
+type StatsFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// StatsBucket - число заказов и GMV в одной группе разреза.
+// GMV считается по payment.amount, для брендов - по total_price товаров бренда
+type StatsBucket struct {
+	Key    string `json:"key"`
+	Orders int64  `json:"orders"`
+	GMV    int64  `json:"gmv"`
+}
+
+// OrderStats - сводная статистика заказов за период
+type OrderStats struct {
+	Orders int64 `json:"orders"`
+	GMV    int64 `json:"gmv"`
+	// AvgBasket - средняя сумма заказа
+	AvgBasket float64 `json:"avg_basket"`
+	// AvgItems - среднее число товаров в заказе
+	AvgItems float64 `json:"avg_items"`
+
+	ByDay             []StatsBucket `json:"by_day"`
+	ByDeliveryService []StatsBucket `json:"by_delivery_service"`
+	ByProvider        []StatsBucket `json:"by_provider"`
+	ByCurrency        []StatsBucket `json:"by_currency"`
+	ByBrand           []StatsBucket `json:"by_brand"`
+}
+
+// Breakdown - группы статистики по разрезу
+func (s *OrderStats) Breakdown(dimension StatsDimension) ([]StatsBucket, bool) {
+	switch dimension {
+	case StatsByDay:
+		return s.ByDay, true
+	case StatsByDeliveryService:
+		return s.ByDeliveryService, true
+	case StatsByProvider:
+		return s.ByProvider, true
+	case StatsByCurrency:
+		return s.ByCurrency, true
+	case StatsByBrand:
+		return s.ByBrand, true
+	default:
+		return nil, false
+	}
+}
+
+// SetBreakdown - записываем группы статистики по разрезу
+func (s *OrderStats) SetBreakdown(dimension StatsDimension, buckets []StatsBucket) {
+	switch dimension {
+	case StatsByDay:
+		s.ByDay = buckets
+	case StatsByDeliveryService:
+		s.ByDeliveryService = buckets
+	case StatsByProvider:
+		s.ByProvider = buckets
+	case StatsByCurrency:
+		s.ByCurrency = buckets
+	case StatsByBrand:
+		s.ByBrand = buckets
+	}
+}
@@ -0,0 +1,120 @@
+package export
+
+import (
+	"WbDemoProject/Internal/entity"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writer - построчная запись заказов в выгрузку
+type Writer interface {
+	WriteOrder(order *entity.Order) error
+	Flush() error
+}
+
+// csvHeader - колонки CSV, каждая строка это одна позиция заказа
+var csvHeader = []string{
+	"order_uid", "track_number", "entry", "locale", "customer_id", "delivery_service", "shardkey", "sm_id",
+	"date_created", "oof_shard",
+	"delivery_name", "delivery_phone", "delivery_zip", "delivery_city", "delivery_address", "delivery_region",
+	"delivery_email",
+	"payment_transaction", "payment_request_id", "payment_currency", "payment_provider", "payment_amount",
+	"payment_dt", "payment_bank", "payment_delivery_cost", "payment_goods_total", "payment_custom_fee",
+	"item_chrt_id", "item_track_number", "item_price", "item_rid", "item_name", "item_sale", "item_size",
+	"item_total_price", "item_nm_id", "item_brand", "item_status",
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// NewCSV - CSV выгрузка, позиции заказа разворачиваются в отдельные строки
+func NewCSV(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteOrder(order *entity.Order) error {
+	if !c.headerWritten {
+		if err := c.w.Write(csvHeader); err != nil {
+			return fmt.Errorf("export: error writing csv header: %w", err)
+		}
+		c.headerWritten = true
+	}
+
+	base := []string{
+		order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.CustomerID, order.DeliveryService,
+		order.ShardKey, strconv.Itoa(order.SmID), order.DateCreated.UTC().Format(time.RFC3339), order.OofShard,
+		order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip, order.Delivery.City, order.Delivery.Address,
+		order.Delivery.Region, order.Delivery.Email,
+		order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
+		strconv.Itoa(order.Payment.Amount), strconv.FormatInt(order.Payment.PaymentDT, 10), order.Payment.Bank,
+		strconv.Itoa(order.Payment.DeliveryCost), strconv.Itoa(order.Payment.GoodsTotal),
+		strconv.Itoa(order.Payment.CustomFee),
+	}
+
+	// заказ без позиций все равно попадает в выгрузку одной строкой
+	if len(order.Items) == 0 {
+		return c.write(append(base, make([]string, len(csvHeader)-len(base))...))
+	}
+
+	for _, item := range order.Items {
+		record := append(base[:len(base):len(base)],
+			strconv.Itoa(item.ChrtID), item.TrackNumber, strconv.Itoa(item.Price), item.Rid, item.Name,
+			strconv.Itoa(item.Sale), item.Size, strconv.Itoa(item.TotalPrice), strconv.Itoa(item.NmID), item.Brand,
+			strconv.Itoa(item.Status),
+		)
+		if err := c.write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *csvWriter) write(record []string) error {
+	if err := c.w.Write(record); err != nil {
+		return fmt.Errorf("export: error writing csv row: %w", err)
+	}
+
+	return nil
+}
+
+func (c *csvWriter) Flush() error {
+	// пустая выгрузка тоже должна содержать заголовок
+	if !c.headerWritten {
+		if err := c.w.Write(csvHeader); err != nil {
+			return fmt.Errorf("export: error writing csv header: %w", err)
+		}
+		c.headerWritten = true
+	}
+
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSON - выгрузка NDJSON, один заказ целиком на строку
+func NewNDJSON(w io.Writer) Writer {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) WriteOrder(order *entity.Order) error {
+	if err := n.enc.Encode(order); err != nil {
+		return fmt.Errorf("export: error encoding order: %w", err)
+	}
+
+	return nil
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
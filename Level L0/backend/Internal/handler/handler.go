@@ -1,19 +1,35 @@
 package handler
 
 import (
-	"WbDemoProject/Internal/usecase"
+	"WbDemoProject/Internal/cache"
+	"WbDemoProject/Internal/entity"
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// OrderService - бизнес-логика, которую использует handler. Заказы из нее уже
+// отредактированы по роли запроса (redaction.Service), сам handler поля не скрывает
+type OrderService interface {
+	GetOrder(ctx context.Context, orderUID string) (*entity.Order, error)
+	GetOrderHistory(ctx context.Context, orderUID string) ([]entity.OrderRevision, error)
+	ListOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderPage, error)
+	ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error
+	OrderStats(ctx context.Context, filter entity.StatsFilter) (*entity.OrderStats, error)
+	CacheStats() cache.Stats
+}
+
 type Handler struct {
-	usecase *usecase.Usecase
+	usecase      OrderService
+	dependencies map[string]Pinger
 }
 
-func New(usecase *usecase.Usecase) *Handler {
+func New(usecase OrderService, dependencies map[string]Pinger) *Handler {
 	return &Handler{
-		usecase: usecase,
+		usecase:      usecase,
+		dependencies: dependencies,
 	}
 }
 
@@ -28,3 +44,26 @@ func (h *Handler) GetOrder(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, data)
 }
+
+// GetOrderHistory - история версий заказа
+func (h *Handler) GetOrderHistory(ctx *gin.Context) {
+	orderUID := ctx.Param("order_uid")
+
+	revisions, err := h.usecase.GetOrderHistory(ctx, orderUID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get order history"})
+		return
+	}
+
+	if len(revisions) == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revisions)
+}
+
+// GetCacheStats - счетчики кэша: попадания, промахи, вытеснения
+func (h *Handler) GetCacheStats(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.usecase.CacheStats())
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"WbDemoProject/Internal/auth"
+	"WbDemoProject/Internal/cache"
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/redaction"
+	"WbDemoProject/Internal/repository/memory"
+	"WbDemoProject/Internal/testutil"
+	"WbDemoProject/Internal/usecase"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := memory.New()
+	if _, err := store.SaveOrderInDB(context.Background(), testutil.ValidOrder("order-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	orderCache := cache.NewLRU(cache.Options[*entity.Order]{MaxEntries: 10})
+	h := New(redaction.NewService(usecase.New(store, orderCache), redaction.New(redaction.DefaultPolicies())), nil)
+
+	router := gin.New()
+	api := router.Group("/", auth.Middleware(auth.Keys{"admin-key": auth.RoleAdmin, "support-key": auth.RoleSupport, "finance-key": auth.RoleFinance}))
+	api.GET("/orders", h.ListOrders)
+	api.GET("/orders/export", h.ExportOrders)
+	api.GET("/order/:order_uid", h.GetOrder)
+	api.GET("/order/:order_uid/history", h.GetOrderHistory)
+	api.GET("/stats", h.GetStats)
+	api.GET("/stats/:dimension", h.GetStatsBreakdown)
+
+	return router
+}
+
+func TestHandler(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+		check      func(t *testing.T, body []byte)
+	}{
+		{
+			name:       "admin sees full order",
+			path:       "/order/order-1",
+			key:        "admin-key",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var order entity.Order
+				if err := json.Unmarshal(body, &order); err != nil {
+					t.Fatal(err)
+				}
+				if order.Payment.Transaction != "order-1" || order.Delivery.Phone != "+9720000000" {
+					t.Errorf("admin got redacted order: %+v", order)
+				}
+			},
+		},
+		{
+			name:       "support sees redacted order",
+			path:       "/order/order-1",
+			key:        "support-key",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var order entity.Order
+				if err := json.Unmarshal(body, &order); err != nil {
+					t.Fatal(err)
+				}
+				if order.Payment.Transaction != "" {
+					t.Errorf("transaction = %q, want hidden", order.Payment.Transaction)
+				}
+				if order.Delivery.Phone == "+9720000000" {
+					t.Errorf("phone is not masked")
+				}
+			},
+		},
+		{
+			name:       "support sees redacted list",
+			path:       "/orders?limit=5",
+			key:        "support-key",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var page entity.OrderPage
+				if err := json.Unmarshal(body, &page); err != nil {
+					t.Fatal(err)
+				}
+				if len(page.Orders) != 1 || page.Orders[0].Payment.Transaction != "" {
+					t.Errorf("page = %+v, want one order with hidden transaction", page)
+				}
+			},
+		},
+		{
+			name:       "finance sees redacted history",
+			path:       "/order/order-1/history",
+			key:        "finance-key",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var revisions []entity.OrderRevision
+				if err := json.Unmarshal(body, &revisions); err != nil {
+					t.Fatal(err)
+				}
+				if len(revisions) == 0 || revisions[0].Order.Delivery.Phone != "" {
+					t.Errorf("revisions = %+v, want hidden phone", revisions)
+				}
+			},
+		},
+		{
+			name:       "support gets redacted export",
+			path:       "/orders/export?from=2021-11-01&to=2021-12-01&format=ndjson",
+			key:        "support-key",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				if !bytes.Contains(body, []byte(`"order-1"`)) || bytes.Contains(body, []byte("+9720000000")) {
+					t.Errorf("export = %s, want order-1 with masked phone", body)
+				}
+			},
+		},
+		{name: "unknown order", path: "/order/missing", key: "admin-key", wantStatus: http.StatusNotFound},
+		{name: "missing api key", path: "/order/order-1", wantStatus: http.StatusUnauthorized},
+		{name: "wrong api key", path: "/order/order-1", key: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "bad limit", path: "/orders?limit=abc", key: "admin-key", wantStatus: http.StatusBadRequest},
+		{name: "bad cursor", path: "/orders?cursor=%21%21", key: "admin-key", wantStatus: http.StatusBadRequest},
+		{
+			name:       "stats",
+			path:       "/stats?from=2021-11-01&to=2021-12-01",
+			key:        "support-key",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var stats entity.OrderStats
+				if err := json.Unmarshal(body, &stats); err != nil {
+					t.Fatal(err)
+				}
+				if stats.Orders != 1 || len(stats.ByDay) != 1 || stats.ByDay[0].Key != "2021-11-26" {
+					t.Errorf("stats = %+v, want one order on 2021-11-26", stats)
+				}
+			},
+		},
+		{name: "stats by brand", path: "/stats/brand", key: "finance-key", wantStatus: http.StatusOK},
+		{name: "unknown stats dimension", path: "/stats/color", key: "admin-key", wantStatus: http.StatusNotFound},
+		{name: "stats bad range", path: "/stats?from=2022-01-01&to=2021-01-01", key: "admin-key", wantStatus: http.StatusBadRequest},
+		{
+			name:       "list orders",
+			path:       "/orders?limit=5",
+			key:        "admin-key",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var page entity.OrderPage
+				if err := json.Unmarshal(body, &page); err != nil {
+					t.Fatal(err)
+				}
+				if len(page.Orders) != 1 || page.NextCursor != "" {
+					t.Errorf("page = %+v, want one order without next cursor", page)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(auth.APIKeyHeader, tt.key)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if tt.check != nil {
+				tt.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+// failingExport - выгрузка, которая ломается после первого заказа
+type failingExport struct {
+	OrderService
+}
+
+func (f failingExport) ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error {
+	if err := fn(testutil.ValidOrder("order-1")); err != nil {
+		return err
+	}
+
+	return errors.New("connection to db lost")
+}
+
+func TestExportOrdersAbortsOnError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/orders/export", New(failingExport{}, nil).ExportOrders)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// оборванная выгрузка не должна выглядеть для клиента как полная: соединение рвется
+	// до заголовков или посреди тела, смотря сколько успело уйти из буфера
+	resp, err := http.Get(server.URL + "/orders/export?from=2021-11-01&to=2021-12-01&format=ndjson")
+	if err == nil {
+		defer resp.Body.Close()
+		_, err = io.ReadAll(resp.Body)
+	}
+	if err == nil {
+		t.Error("export finished without error, want aborted response")
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// healthCheckTimeout - сколько ждем ответа от каждой зависимости
+const healthCheckTimeout = 2 * time.Second
+
+// Pinger - зависимость, доступность которой проверяем в /healthz и /readyz
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Healthz - сервис жив, в ответе состояние зависимостей
+func (h *Handler) Healthz(ctx *gin.Context) {
+	_, checks := h.checkDependencies(ctx)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
+
+// Readyz - сервис готов принимать запросы, только если доступны все зависимости
+func (h *Handler) Readyz(ctx *gin.Context) {
+	ready, checks := h.checkDependencies(ctx)
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+func (h *Handler) checkDependencies(ctx context.Context) (bool, map[string]string) {
+	ready := true
+	checks := make(map[string]string, len(h.dependencies))
+
+	for name, dependency := range h.dependencies {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := dependency.Ping(checkCtx)
+		cancel()
+
+		if err != nil {
+			ready = false
+			checks[name] = err.Error()
+			continue
+		}
+
+		checks[name] = "ok"
+	}
+
+	return ready, checks
+}
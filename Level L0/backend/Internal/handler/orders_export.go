@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/export"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery - через сколько заказов сбрасываем буфер клиенту
+const exportFlushEvery = 100
+
+// ExportOrders - выгрузка заказов за период: /orders/export?from=&to=&format=csv|ndjson
+func (h *Handler) ExportOrders(ctx *gin.Context) {
+	from, err := parseDateParam(ctx, "from")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	to, err := parseDateParam(ctx, "to")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if from == nil || to == nil || !from.Before(*to) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required and from must be before to"})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "csv")
+
+	var writer export.Writer
+	switch format {
+	case "csv":
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		writer = export.NewCSV(ctx.Writer)
+	case "ndjson":
+		ctx.Header("Content-Type", "application/x-ndjson")
+		writer = export.NewNDJSON(ctx.Writer)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or ndjson"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="orders_%s_%s.%s"`,
+		from.Format("20060102"), to.Format("20060102"), format))
+	ctx.Status(http.StatusOK)
+
+	written := 0
+	err = h.usecase.ExportOrders(ctx, *from, *to, func(order *entity.Order) error {
+		if err := writer.WriteOrder(order); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+
+		return nil
+	})
+	if err != nil {
+		// заголовки уже отправлены, поменять статус нельзя. Обычный конец ответа клиент принял бы
+		// за полную выгрузку, поэтому рвем соединение: net/http делает это молча на ErrAbortHandler
+		log.Printf("export orders failed after %d orders: %v", written, err)
+		panic(http.ErrAbortHandler)
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Printf("export orders flush failed: %v", err)
+	}
+}
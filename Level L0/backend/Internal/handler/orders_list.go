@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"WbDemoProject/Internal/entity"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListOrders - список заказов с фильтрами:
+// customer_id, date_from, date_to, delivery_service, provider, currency, brand, nm_id, q, cursor, limit
+func (h *Handler) ListOrders(ctx *gin.Context) {
+	filter, err := parseOrderFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.usecase.ListOrders(ctx, filter)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list orders"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func parseOrderFilter(ctx *gin.Context) (entity.OrderFilter, error) {
+	filter := entity.OrderFilter{
+		CustomerID:      ctx.Query("customer_id"),
+		DeliveryService: ctx.Query("delivery_service"),
+		Provider:        ctx.Query("provider"),
+		Currency:        ctx.Query("currency"),
+		Brand:           ctx.Query("brand"),
+		Query:           ctx.Query("q"),
+		Cursor:          ctx.Query("cursor"),
+	}
+
+	var err error
+
+	if filter.DateFrom, err = parseDateParam(ctx, "date_from"); err != nil {
+		return filter, err
+	}
+
+	if filter.DateTo, err = parseDateParam(ctx, "date_to"); err != nil {
+		return filter, err
+	}
+
+	if value := ctx.Query("nm_id"); value != "" {
+		if filter.NmID, err = strconv.Atoi(value); err != nil {
+			return filter, fmt.Errorf("invalid nm_id %q", value)
+		}
+	}
+
+	if value := ctx.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", value)
+		}
+	}
+
+	return filter, nil
+}
+
+// parseDateParam - дата в RFC3339 или YYYY-MM-DD
+func parseDateParam(ctx *gin.Context, name string) (*time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid %s %q, expected RFC3339 or YYYY-MM-DD", name, value)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"WbDemoProject/Internal/entity"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStats - сводная статистика заказов и все разрезы: /stats?from=&to=
+func (h *Handler) GetStats(ctx *gin.Context) {
+	stats, ok := h.orderStats(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// GetStatsBreakdown - один разрез статистики: /stats/day, /stats/delivery_service,
+// /stats/provider, /stats/currency, /stats/brand
+func (h *Handler) GetStatsBreakdown(ctx *gin.Context) {
+	dimension := entity.StatsDimension(ctx.Param("dimension"))
+
+	// разрез проверяем до запроса в БД
+	if !slices.Contains(entity.StatsDimensions, dimension) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "unknown stats dimension", "dimensions": entity.StatsDimensions})
+		return
+	}
+
+	stats, ok := h.orderStats(ctx)
+	if !ok {
+		return
+	}
+
+	buckets, _ := stats.Breakdown(dimension)
+
+	ctx.JSON(http.StatusOK, gin.H{"dimension": dimension, "buckets": buckets})
+}
+
+// orderStats - разбираем период и получаем статистику, при ошибке ответ уже отправлен
+func (h *Handler) orderStats(ctx *gin.Context) (*entity.OrderStats, bool) {
+	from, err := parseDateParam(ctx, "from")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	to, err := parseDateParam(ctx, "to")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return nil, false
+	}
+
+	stats, err := h.usecase.OrderStats(ctx, entity.StatsFilter{From: from, To: to})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get order stats"})
+		return nil, false
+	}
+
+	return stats, true
+}
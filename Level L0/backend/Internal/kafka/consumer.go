@@ -2,9 +2,14 @@ package kafka
 
 import (
 	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/metrics"
+	"WbDemoProject/Internal/validation"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -13,11 +18,34 @@ type OrderHandler interface {
 	HandleOrder(ctx context.Context, order *entity.Order) error
 }
 
+// MessageReader - источник сообщений, в проде это *kafka.Reader
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// MessageWriter - запись сообщений в DLQ, в проде это *kafka.Writer
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// minRetryDelay, maxRetryDelay - пауза между повторами обработки и записи в DLQ, удваивается до максимума
+const (
+	minRetryDelay = 500 * time.Millisecond
+	maxRetryDelay = 30 * time.Second
+)
+
 type Consumer struct {
-	reader *kafka.Reader
+	reader    MessageReader
+	dlqWriter MessageWriter
+	brokers   []string
+	// retryDelay - первая пауза перед повтором
+	retryDelay time.Duration
 }
 
-func New(brokers []string, topic, groupID string) *Consumer {
+func New(brokers []string, topic, groupID, dlqTopic string) *Consumer {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:  brokers,
 		Topic:    topic,
@@ -26,43 +54,172 @@ func New(brokers []string, topic, groupID string) *Consumer {
 		MaxBytes: 10e6,
 	})
 
-	return &Consumer{reader: reader}
+	dlqWriter := &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  dlqTopic,
+		Balancer:               &kafka.LeastBytes{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}
+
+	return &Consumer{reader: reader, dlqWriter: dlqWriter, brokers: brokers, retryDelay: minRetryDelay}
 }
 
-// StartConsumer - читаем сообщения и отправляем в бизнес логику
+// NewWithReader - консюмер поверх готовых reader и writer (например, фейковых в тестах)
+func NewWithReader(reader MessageReader, dlqWriter MessageWriter, brokers []string) *Consumer {
+	return &Consumer{reader: reader, dlqWriter: dlqWriter, brokers: brokers, retryDelay: minRetryDelay}
+}
+
+// StartConsumer - читаем сообщения и отправляем в бизнес логику.
+// После отмены ctx новые сообщения не читаются, а уже полученное обрабатывается и фиксируется до конца.
+// Сообщение, которое так и не удалось обработать, не фиксируется, и консюмер останавливается:
+// коммит следующего сдвинул бы offset за него
 func (consumer *Consumer) StartConsumer(ctx context.Context, handler OrderHandler) error {
 	for {
-		msg, err := consumer.reader.ReadMessage(ctx)
+		msg, err := consumer.reader.FetchMessage(ctx)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
 			log.Printf("Error reading message: %v", err)
-			continue
+			return err
 		}
 
+		// отставание от конца партиции на момент чтения
+		metrics.ConsumerLag(msg.Partition, msg.HighWaterMark-msg.Offset-1)
+
 		var order entity.Order
 		err = json.Unmarshal(msg.Value, &order)
 		if err != nil {
 			log.Printf("Error unmarshalling message: %v", err)
 
-			if err := consumer.reader.CommitMessages(ctx, msg); err != nil {
-				log.Printf("Error committing invalid message: %v", err)
+			if err := consumer.reject(ctx, msg, []validation.FieldError{{Field: "payload", Message: err.Error()}}); err != nil {
+				log.Printf("Stopping consumer: %v", err)
+				return nil
 			}
 			continue
 		}
 
-		// передаем заказ в обработчик
-		if err := handler.HandleOrder(ctx, &order); err != nil {
+		if err := consumer.handle(ctx, handler, msg, &order); err != nil {
+			log.Printf("Stopping consumer: %v", err)
+			return nil
+		}
+	}
+}
+
+// handle - передаем заказ в обработчик и фиксируем сообщение. Временные ошибки (например,
+// недоступна БД) повторяются, невалидный заказ уходит в DLQ. Ошибка - ctx отменен раньше,
+// чем заказ удалось обработать
+func (consumer *Consumer) handle(ctx context.Context, handler OrderHandler, msg kafka.Message, order *entity.Order) error {
+	// обработка и коммит не должны обрываться на середине при остановке сервиса
+	processCtx := context.WithoutCancel(ctx)
+
+	var validationErrs validation.Errors
+	err := consumer.retry(ctx, func() error {
+		err := handler.HandleOrder(processCtx, order)
+		// невалидный заказ повторно не обработать, повторять нечего
+		if errors.As(err, &validationErrs) {
+			return nil
+		}
+		if err != nil {
 			log.Printf("Error handling order: %v", err)
+		}
+		return err
+	})
+	if err != nil {
+		metrics.ConsumerMessage(metrics.ResultFailed)
+		return fmt.Errorf("message at offset %d not handled: %w", msg.Offset, err)
+	}
 
-			continue
+	if validationErrs != nil {
+		log.Printf("Error handling order: %v", validationErrs)
+		return consumer.reject(ctx, msg, validationErrs)
+	}
+
+	metrics.ConsumerMessage(metrics.ResultProcessed)
+
+	// фиксируем сообщение
+	if err := consumer.reader.CommitMessages(processCtx, msg); err != nil {
+		log.Printf("Error committing message: %v", err)
+	}
+
+	return nil
+}
+
+// reject - отправляем сообщение в DLQ и фиксируем его только после успешной отправки.
+// Запись повторяется, пока не пройдет: коммит следующего сообщения сдвинул бы offset
+// за это, и оно потерялось бы. Ошибка - ctx отменен раньше, консюмер должен остановиться,
+// чтобы после перезапуска прочитать сообщение снова
+func (consumer *Consumer) reject(ctx context.Context, msg kafka.Message, reasons []validation.FieldError) error {
+	// начатую запись и коммит не обрываем, отмена только прекращает повторы
+	processCtx := context.WithoutCancel(ctx)
+
+	err := consumer.retry(ctx, func() error {
+		err := consumer.sendToDLQ(processCtx, msg, reasons)
+		if err != nil {
+			log.Printf("Error sending message to DLQ: %v", err)
+		}
+		return err
+	})
+	if err != nil {
+		metrics.ConsumerMessage(metrics.ResultFailed)
+		return fmt.Errorf("DLQ: message at offset %d not sent: %w", msg.Offset, err)
+	}
+
+	metrics.ConsumerMessage(metrics.ResultDeadLettered)
+
+	if err := consumer.reader.CommitMessages(processCtx, msg); err != nil {
+		log.Printf("Error committing rejected message: %v", err)
+	}
+
+	return nil
+}
+
+// retry - вызываем fn, пока она не вернет nil, с паузой от retryDelay до maxRetryDelay.
+// Ошибка - последняя ошибка fn, если ctx отменили раньше
+func (consumer *Consumer) retry(ctx context.Context, fn func() error) error {
+	wait := consumer.retryDelay
+
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
 		}
+		wait = min(wait*2, maxRetryDelay)
+	}
+}
+
+// Ping - проверяем что хотя бы один брокер доступен
+func (consumer *Consumer) Ping(ctx context.Context) error {
+	if len(consumer.brokers) == 0 {
+		return errors.New("kafka: no brokers configured")
+	}
+
+	var errs []error
 
-		// фиксируем сообщение
-		if err := consumer.reader.CommitMessages(ctx, msg); err != nil {
-			log.Printf("Error committing message: %v", err)
+	for _, broker := range consumer.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
+
+		return conn.Close()
 	}
+
+	return fmt.Errorf("kafka brokers unavailable: %w", errors.Join(errs...))
 }
 
 func (consumer *Consumer) Close() error {
-	return consumer.reader.Close()
+	readerErr := consumer.reader.Close()
+	writerErr := consumer.dlqWriter.Close()
+
+	return errors.Join(readerErr, writerErr)
 }
@@ -0,0 +1,218 @@
+package kafka
+
+import (
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/testutil"
+	"WbDemoProject/Internal/validation"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeReader - отдает заранее заданные сообщения, затем ждет отмены контекста
+type fakeReader struct {
+	messages  []kafka.Message
+	committed []int64
+	cancel    context.CancelFunc
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	if len(r.messages) == 0 {
+		r.cancel()
+		<-ctx.Done()
+		return kafka.Message{}, ctx.Err()
+	}
+
+	msg := r.messages[0]
+	r.messages = r.messages[1:]
+
+	return msg, nil
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+
+	return nil
+}
+
+func (r *fakeReader) Close() error { return nil }
+
+// fakeWriter - первые failures записей возвращают err, при failures < 0 - все
+type fakeWriter struct {
+	messages []kafka.Message
+	err      error
+	failures int
+	onFail   func()
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.failures != 0 {
+		w.failures--
+		if w.onFail != nil {
+			w.onFail()
+		}
+		return w.err
+	}
+
+	w.messages = append(w.messages, msgs...)
+
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+type handlerFunc func(ctx context.Context, order *entity.Order) error
+
+func (f handlerFunc) HandleOrder(ctx context.Context, order *entity.Order) error {
+	return f(ctx, order)
+}
+
+// TestStartConsumer - за сообщением 42 из теста всегда идет нормальный заказ 43. Сообщение,
+// которое не удалось обработать или отправить в DLQ, не фиксируется, и 43 тоже: его коммит
+// сдвинул бы offset за 42
+func TestStartConsumer(t *testing.T) {
+	valid, err := json.Marshal(testutil.ValidOrder("order-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := json.Marshal(testutil.ValidOrder("order-2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbDown := errors.New("db is down")
+
+	tests := []struct {
+		name       string
+		value      []byte
+		handlerErr error
+		// handlerFailures, dlqFailures - сколько первых вызовов для 42 завершаются ошибкой:
+		// 0 - все (у обработчика), -1 - все, а после первой ошибки сервис останавливают
+		handlerFailures int
+		dlqFailures     int
+		wantCommitted   []int64
+		wantDLQ         bool
+	}{
+		{name: "processed order is committed", value: valid, wantCommitted: []int64{42, 43}},
+		{
+			name:            "handler error is retried before commit",
+			value:           valid,
+			handlerErr:      dbDown,
+			handlerFailures: 2,
+			wantCommitted:   []int64{42, 43},
+		},
+		{
+			name:            "handler error until shutdown commits nothing past the message",
+			value:           valid,
+			handlerErr:      dbDown,
+			handlerFailures: -1,
+		},
+		{name: "bad json goes to DLQ", value: []byte("{not json"), wantCommitted: []int64{42, 43}, wantDLQ: true},
+		{
+			name:          "validation error goes to DLQ",
+			value:         valid,
+			handlerErr:    validation.Errors{{Field: "payment.currency", Message: "unsupported"}},
+			wantCommitted: []int64{42, 43},
+			wantDLQ:       true,
+		},
+		{
+			name:          "failed DLQ write is retried before commit",
+			value:         []byte("{not json"),
+			dlqFailures:   2,
+			wantCommitted: []int64{42, 43},
+			wantDLQ:       true,
+		},
+		{
+			name:        "DLQ failure until shutdown commits nothing past the message",
+			value:       []byte("{not json"),
+			dlqFailures: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			reader := &fakeReader{
+				messages: []kafka.Message{{Topic: "orders", Offset: 42, Value: tt.value}, {Topic: "orders", Offset: 43, Value: next}},
+				cancel:   cancel,
+			}
+			writer := &fakeWriter{err: errors.New("broker unavailable"), failures: tt.dlqFailures}
+			if tt.dlqFailures < 0 {
+				writer.onFail = cancel
+			}
+			consumer := NewWithReader(reader, writer, nil)
+			consumer.retryDelay = time.Millisecond
+
+			calls := 0
+			err := consumer.StartConsumer(ctx, handlerFunc(func(_ context.Context, order *entity.Order) error {
+				if order.OrderUID != "order-1" || tt.handlerErr == nil {
+					return nil
+				}
+
+				calls++
+				if tt.handlerFailures > 0 && calls > tt.handlerFailures {
+					return nil
+				}
+				if tt.handlerFailures < 0 {
+					cancel()
+				}
+				return tt.handlerErr
+			}))
+			if err != nil {
+				t.Fatalf("StartConsumer() error = %v", err)
+			}
+
+			if !slices.Equal(reader.committed, tt.wantCommitted) {
+				t.Errorf("committed = %v, want %v", reader.committed, tt.wantCommitted)
+			}
+
+			if gotDLQ := len(writer.messages) == 1; gotDLQ != tt.wantDLQ {
+				t.Fatalf("sent to DLQ = %v, want %v", gotDLQ, tt.wantDLQ)
+			}
+
+			if tt.wantDLQ {
+				headers := make(map[string]string)
+				for _, h := range writer.messages[0].Headers {
+					headers[h.Key] = string(h.Value)
+				}
+
+				if headers[HeaderDLQOffset] != "42" || headers[HeaderDLQTopic] != "orders" {
+					t.Errorf("DLQ headers = %v, want original topic and offset", headers)
+				}
+				if string(writer.messages[0].Value) != string(tt.value) {
+					t.Errorf("DLQ value = %q, want original %q", writer.messages[0].Value, tt.value)
+				}
+			}
+		})
+	}
+}
+
+func TestStartConsumerReadError(t *testing.T) {
+	reader := &errReader{err: errors.New("connection refused")}
+	consumer := NewWithReader(reader, &fakeWriter{}, nil)
+
+	err := consumer.StartConsumer(context.Background(), handlerFunc(func(context.Context, *entity.Order) error {
+		return nil
+	}))
+	if !errors.Is(err, reader.err) {
+		t.Fatalf("StartConsumer() error = %v, want %v", err, reader.err)
+	}
+}
+
+type errReader struct {
+	fakeReader
+	err error
+}
+
+func (r *errReader) FetchMessage(context.Context) (kafka.Message, error) {
+	return kafka.Message{}, r.err
+}
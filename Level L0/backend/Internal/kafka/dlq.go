@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"WbDemoProject/Internal/validation"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// заголовки, которые добавляются к сообщению в DLQ
+const (
+	HeaderDLQErrors    = "x-dlq-errors"
+	HeaderDLQTopic     = "x-dlq-original-topic"
+	HeaderDLQPartition = "x-dlq-original-partition"
+	HeaderDLQOffset    = "x-dlq-original-offset"
+	HeaderDLQFailedAt  = "x-dlq-failed-at"
+)
+
+// sendToDLQ - отправляем исходное сообщение в DLQ.
+// Тело и заголовки сохраняются как есть, чтобы сообщение можно было переотправить,
+// ошибки валидации и координаты исходного сообщения кладем в отдельные заголовки.
+func (consumer *Consumer) sendToDLQ(ctx context.Context, msg kafka.Message, reasons []validation.FieldError) error {
+	reasonsJSON, err := json.Marshal(reasons)
+	if err != nil {
+		return fmt.Errorf("DLQ: error marshalling reasons: %w", err)
+	}
+
+	headers := make([]kafka.Header, 0, len(msg.Headers)+5)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQErrors, Value: reasonsJSON},
+		kafka.Header{Key: HeaderDLQTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+
+	err = consumer.dlqWriter.WriteMessages(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("DLQ: error writing message offset %d: %w", msg.Offset, err)
+	}
+
+	return nil
+}
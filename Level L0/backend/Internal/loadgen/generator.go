@@ -0,0 +1,234 @@
+package loadgen
+
+import (
+	"WbDemoProject/Internal/entity"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{"Ivan", "Anna", "Petr", "Olga", "Test", "Maria", "Sergey", "Elena"}
+	lastNames  = []string{"Ivanov", "Petrova", "Sidorov", "Testov", "Smirnova", "Kuznetsov"}
+	cities     = []string{"Moscow", "Saint Petersburg", "Kazan", "Novosibirsk", "Kiryat Mozkin", "Minsk"}
+	streets    = []string{"Lenina", "Ploshad Mira", "Tverskaya", "Nevsky", "Sadovaya"}
+	regions    = []string{"Moscow", "Leningrad", "Tatarstan", "Kraiot", "Novosibirsk"}
+	brands     = []string{"Vivienne Sabo", "Nike", "Adidas", "Xiaomi", "Samsung", "Zara", "Lego"}
+	goods      = []string{"Mascaras", "Sneakers", "T-shirt", "Phone case", "Headphones", "Backpack", "Lamp"}
+	currencies = []string{"RUB", "USD", "EUR", "KZT", "BYN"}
+	providers  = []string{"wbpay", "sbp", "card", "applepay"}
+	banks      = []string{"alpha", "sber", "tinkoff", "vtb"}
+	services   = []string{"meest", "cdek", "boxberry", "wb"}
+	locales    = []string{"en", "ru"}
+)
+
+// Options - параметры генерации заказов
+type Options struct {
+	MinItems int
+	MaxItems int
+	// InvalidRatio - доля заведомо невалидных сообщений, от 0 до 1
+	InvalidRatio float64
+	// DuplicateRatio - доля повторных отправок уже отправленных сообщений, от 0 до 1
+	DuplicateRatio float64
+	Seed           uint64
+}
+
+// Kind - что именно сгенерировано
+type Kind int
+
+const (
+	KindValid Kind = iota
+	KindInvalid
+	KindDuplicate
+)
+
+// Message - сообщение для отправки
+type Message struct {
+	Key   []byte
+	Value []byte
+	Kind  Kind
+}
+
+// duplicatesWindow - из скольких последних сообщений выбираем дубликаты
+const duplicatesWindow = 1000
+
+// Generator - генератор случайных, но согласованных заказов
+type Generator struct {
+	opts   Options
+	rnd    *rand.Rand
+	recent []Message
+}
+
+// NewGenerator - конструктор генератора
+func NewGenerator(opts Options) *Generator {
+	if opts.MinItems < 1 {
+		opts.MinItems = 1
+	}
+	if opts.MaxItems < opts.MinItems {
+		opts.MaxItems = opts.MinItems
+	}
+
+	return &Generator{
+		opts: opts,
+		rnd:  rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15)),
+	}
+}
+
+// Next - следующее сообщение: валидный заказ, невалидный или повтор
+func (g *Generator) Next() (Message, error) {
+	if len(g.recent) > 0 && g.rnd.Float64() < g.opts.DuplicateRatio {
+		msg := g.recent[g.rnd.IntN(len(g.recent))]
+		msg.Kind = KindDuplicate
+
+		return msg, nil
+	}
+
+	order := g.Order()
+	kind := KindValid
+
+	var value []byte
+	if g.rnd.Float64() < g.opts.InvalidRatio {
+		kind = KindInvalid
+		value = g.corrupt(&order)
+	}
+
+	if value == nil {
+		var err error
+		value, err = json.Marshal(order)
+		if err != nil {
+			return Message{}, fmt.Errorf("loadgen: error marshalling order: %w", err)
+		}
+	}
+
+	msg := Message{Key: []byte(order.OrderUID), Value: value, Kind: kind}
+	g.remember(msg)
+
+	return msg, nil
+}
+
+// Order - случайный заказ, проходящий валидацию сервиса
+func (g *Generator) Order() entity.Order {
+	track := "WBIL" + g.upper(10)
+	itemsCount := g.opts.MinItems + g.rnd.IntN(g.opts.MaxItems-g.opts.MinItems+1)
+
+	items := make([]entity.Item, 0, itemsCount)
+	goodsTotal := 0
+	for i := 0; i < itemsCount; i++ {
+		price := 100 + g.rnd.IntN(10000)
+		sale := g.rnd.IntN(60)
+		total := price * (100 - sale) / 100
+		goodsTotal += total
+
+		items = append(items, entity.Item{
+			ChrtID:      1000000 + g.rnd.IntN(9000000),
+			TrackNumber: track,
+			Price:       price,
+			Rid:         g.hex(20),
+			Name:        pick(g.rnd, goods),
+			Sale:        sale,
+			Size:        fmt.Sprint(g.rnd.IntN(6)),
+			TotalPrice:  total,
+			NmID:        1000000 + g.rnd.IntN(9000000),
+			Brand:       pick(g.rnd, brands),
+			Status:      202,
+		})
+	}
+
+	deliveryCost := g.rnd.IntN(2000)
+	customFee := 0
+	if g.rnd.IntN(10) == 0 {
+		customFee = g.rnd.IntN(500)
+	}
+
+	first, last := pick(g.rnd, firstNames), pick(g.rnd, lastNames)
+	uid := g.hex(15) + "test"
+
+	return entity.Order{
+		OrderUID:    uid,
+		TrackNumber: track,
+		Entry:       "WBIL",
+		Delivery: entity.Delivery{
+			Name:    first + " " + last,
+			Phone:   fmt.Sprintf("+7%010d", g.rnd.IntN(1e10)),
+			Zip:     fmt.Sprintf("%06d", g.rnd.IntN(1e6)),
+			City:    pick(g.rnd, cities),
+			Address: fmt.Sprintf("%s %d", pick(g.rnd, streets), 1+g.rnd.IntN(150)),
+			Region:  pick(g.rnd, regions),
+			Email:   strings.ToLower(first+"."+last) + fmt.Sprintf("%d@example.com", g.rnd.IntN(1000)),
+		},
+		Payment: entity.Payment{
+			Transaction:  uid,
+			Currency:     pick(g.rnd, currencies),
+			Provider:     pick(g.rnd, providers),
+			Amount:       goodsTotal + deliveryCost + customFee,
+			PaymentDT:    time.Now().Unix(),
+			Bank:         pick(g.rnd, banks),
+			DeliveryCost: deliveryCost,
+			GoodsTotal:   goodsTotal,
+			CustomFee:    customFee,
+		},
+		Items:           items,
+		Locale:          pick(g.rnd, locales),
+		CustomerID:      "customer" + fmt.Sprint(g.rnd.IntN(1000)),
+		DeliveryService: pick(g.rnd, services),
+		ShardKey:        fmt.Sprint(g.rnd.IntN(10)),
+		SmID:            g.rnd.IntN(100),
+		DateCreated:     time.Now().UTC().Truncate(time.Second),
+		OofShard:        fmt.Sprint(g.rnd.IntN(3)),
+	}
+}
+
+// corrupt - портим заказ одним из способов, возвращает готовое тело если сломан сам JSON
+func (g *Generator) corrupt(order *entity.Order) []byte {
+	switch g.rnd.IntN(6) {
+	case 0:
+		order.OrderUID = ""
+	case 1:
+		order.Payment.GoodsTotal += 1 + g.rnd.IntN(100)
+	case 2:
+		order.Items[0].TrackNumber = "WBIL" + g.upper(10)
+	case 3:
+		order.Delivery.Email = "not-an-email"
+	case 4:
+		order.Payment.Currency = "XXX"
+	default:
+		value, _ := json.Marshal(order)
+		return value[:len(value)/2]
+	}
+
+	return nil
+}
+
+func (g *Generator) remember(msg Message) {
+	if len(g.recent) < duplicatesWindow {
+		g.recent = append(g.recent, msg)
+		return
+	}
+
+	g.recent[g.rnd.IntN(duplicatesWindow)] = msg
+}
+
+func (g *Generator) hex(n int) string {
+	const alphabet = "0123456789abcdef"
+	return g.fromAlphabet(alphabet, n)
+}
+
+func (g *Generator) upper(n int) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	return g.fromAlphabet(alphabet, n)
+}
+
+func (g *Generator) fromAlphabet(alphabet string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[g.rnd.IntN(len(alphabet))]
+	}
+
+	return string(b)
+}
+
+func pick(rnd *rand.Rand, values []string) string {
+	return values[rnd.IntN(len(values))]
+}
@@ -0,0 +1,135 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// tick - как часто отправляем накопившиеся по расписанию сообщения
+const tick = 10 * time.Millisecond
+
+// RunOptions - параметры нагрузки
+type RunOptions struct {
+	// Rate - сообщений в секунду, 0 - без ограничения
+	Rate float64
+	// Count - сколько всего отправить, 0 - пока не истечет Duration или не отменят ctx
+	Count int
+	// Duration - сколько длится нагрузка, 0 - без ограничения
+	Duration time.Duration
+	// ReportEvery - как часто печатать пропускную способность, 0 - только итог
+	ReportEvery time.Duration
+	Report      io.Writer
+}
+
+// Stats - итоги прогона
+type Stats struct {
+	Sent       int
+	Valid      int
+	Invalid    int
+	Duplicates int
+	Errors     int // сообщения, которые не удалось отправить: неудачный пакет не повторяется
+	Elapsed    time.Duration
+}
+
+// Rate - фактическая скорость отправки
+func (s Stats) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(s.Sent) / s.Elapsed.Seconds()
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("sent=%d valid=%d invalid=%d duplicates=%d errors=%d elapsed=%s rate=%.1f msg/s",
+		s.Sent, s.Valid, s.Invalid, s.Duplicates, s.Errors, s.Elapsed.Round(time.Millisecond), s.Rate())
+}
+
+// Run - генерируем и отправляем сообщения с заданной скоростью
+func Run(ctx context.Context, gen *Generator, sink Sink, opts RunOptions) (Stats, error) {
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var stats Stats
+	start := time.Now()
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	var report <-chan time.Time
+	if opts.ReportEvery > 0 && opts.Report != nil {
+		reportTicker := time.NewTicker(opts.ReportEvery)
+		defer reportTicker.Stop()
+		report = reportTicker.C
+	}
+
+	lastReport, lastSent := start, 0
+
+	for opts.Count == 0 || stats.Sent < opts.Count {
+		select {
+		case <-ctx.Done():
+			stats.Elapsed = time.Since(start)
+			return stats, nil
+		case now := <-report:
+			stats.Elapsed = now.Sub(start)
+			fmt.Fprintf(opts.Report, "%s current=%.1f msg/s\n", stats.String(),
+				float64(stats.Sent-lastSent)/now.Sub(lastReport).Seconds())
+			lastReport, lastSent = now, stats.Sent
+			continue
+		case <-ticker.C:
+		}
+
+		// сколько сообщений должно быть отправлено к текущему моменту
+		due := 1000
+		if opts.Rate > 0 {
+			due = int(time.Since(start).Seconds()*opts.Rate) - stats.Sent
+		}
+		if opts.Count > 0 && stats.Sent+due > opts.Count {
+			due = opts.Count - stats.Sent
+		}
+		if due <= 0 {
+			continue
+		}
+
+		batch := make([]Message, 0, due)
+		for i := 0; i < due; i++ {
+			msg, err := gen.Next()
+			if err != nil {
+				return stats, err
+			}
+			batch = append(batch, msg)
+		}
+
+		if err := sink.Send(ctx, batch); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			stats.Errors += len(batch)
+			if opts.Report != nil {
+				fmt.Fprintf(opts.Report, "send error: %v\n", err)
+			}
+			continue
+		}
+
+		for _, msg := range batch {
+			stats.Sent++
+			switch msg.Kind {
+			case KindInvalid:
+				stats.Invalid++
+			case KindDuplicate:
+				stats.Duplicates++
+			default:
+				stats.Valid++
+			}
+		}
+	}
+
+	stats.Elapsed = time.Since(start)
+
+	return stats, nil
+}
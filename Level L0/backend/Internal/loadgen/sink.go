@@ -0,0 +1,95 @@
+package loadgen
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Sink - куда отправляются сгенерированные сообщения
+type Sink interface {
+	Send(ctx context.Context, msgs []Message) error
+	Close() error
+}
+
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink - отправка в топик Kafka
+func NewKafkaSink(brokers []string, topic string) Sink {
+	return &kafkaSink{writer: &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.LeastBytes{},
+		AllowAutoTopicCreation: true,
+	}}
+}
+
+func (s *kafkaSink) Send(ctx context.Context, msgs []Message) error {
+	batch := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		batch = append(batch, kafka.Message{Key: msg.Key, Value: msg.Value})
+	}
+
+	if err := s.writer.WriteMessages(ctx, batch...); err != nil {
+		return fmt.Errorf("loadgen: error writing to kafka: %w", err)
+	}
+
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+type ndjsonSink struct {
+	w *bufio.Writer
+	// closer - файл, который открыл сам sink, чужой writer (например, os.Stdout) не закрываем
+	closer io.Closer
+}
+
+// NewNDJSONSink - запись сообщений построчно в w, например в stdout. Close только сбрасывает
+// буфер, закрывать w остается вызывающему
+func NewNDJSONSink(w io.Writer) Sink {
+	return &ndjsonSink{w: bufio.NewWriter(w)}
+}
+
+// NewNDJSONFileSink - запись сообщений построчно в новый файл path, Close его закрывает
+func NewNDJSONFileSink(path string) (Sink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("loadgen: error creating file: %w", err)
+	}
+
+	return &ndjsonSink{w: bufio.NewWriter(f), closer: f}, nil
+}
+
+func (s *ndjsonSink) Send(_ context.Context, msgs []Message) error {
+	for _, msg := range msgs {
+		if _, err := s.w.Write(msg.Value); err != nil {
+			return fmt.Errorf("loadgen: error writing message: %w", err)
+		}
+		if err := s.w.WriteByte('\n'); err != nil {
+			return fmt.Errorf("loadgen: error writing message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ndjsonSink) Close() error {
+	err := s.w.Flush()
+
+	// файл закрываем и тогда, когда сбросить буфер не вышло
+	if s.closer != nil {
+		err = errors.Join(err, s.closer.Close())
+	}
+
+	return err
+}
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"WbDemoProject/Internal/cache"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// результаты обработки сообщения консюмером
+const (
+	ResultProcessed    = "processed"
+	ResultFailed       = "failed"
+	ResultDeadLettered = "dead_lettered"
+)
+
+var (
+	consumerMessages = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "orders_consumer_messages_total",
+			Help: "Kafka messages handled by the orders consumer",
+		}, []string{"result"},
+	)
+
+	consumerLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "orders_consumer_lag",
+			Help: "Messages between the last fetched offset and the partition high watermark",
+		}, []string{"partition"},
+	)
+
+	ordersSaved = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "orders_saved_total",
+			Help: "Orders saved to the database by outcome",
+		}, []string{"status"},
+	)
+
+	httpDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latencies",
+			Buckets: []float64{0.005, 0.01, 0.02, 0.05, 0.1, 0.3, 1, 2, 5},
+		}, []string{"method", "route", "status"},
+	)
+)
+
+// ConsumerMessage - учитываем обработанное консюмером сообщение
+func ConsumerMessage(result string) {
+	consumerMessages.WithLabelValues(result).Inc()
+}
+
+// ConsumerLag - отставание консюмера по партиции
+func ConsumerLag(partition int, lag int64) {
+	consumerLag.WithLabelValues(strconv.Itoa(partition)).Set(float64(lag))
+}
+
+// OrderSaved - учитываем сохранение заказа (created, updated, unchanged)
+func OrderSaved(status string) {
+	ordersSaved.WithLabelValues(status).Inc()
+}
+
+// RegisterCache - публикуем счетчики кэша и долю попаданий
+func RegisterCache(name string, stats func() cache.Stats) {
+	labels := prometheus.Labels{"cache": name}
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name:        "cache_hits_total",
+		Help:        "Cache hits",
+		ConstLabels: labels,
+	}, func() float64 { return float64(stats().Hits) })
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name:        "cache_misses_total",
+		Help:        "Cache misses",
+		ConstLabels: labels,
+	}, func() float64 { return float64(stats().Misses) })
+
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name:        "cache_evictions_total",
+		Help:        "Entries evicted because the cache was full",
+		ConstLabels: labels,
+	}, func() float64 { return float64(stats().Evictions) })
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "cache_entries",
+		Help:        "Entries currently in the cache",
+		ConstLabels: labels,
+	}, func() float64 { return float64(stats().Entries) })
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "cache_hit_ratio",
+		Help:        "Share of lookups served from the cache",
+		ConstLabels: labels,
+	}, func() float64 {
+		s := stats()
+		if s.Hits+s.Misses == 0 {
+			return 0
+		}
+
+		return float64(s.Hits) / float64(s.Hits+s.Misses)
+	})
+}
+
+// HTTPMiddleware - гистограмма времени ответа по маршрутам
+func HTTPMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		httpDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -3,88 +3,367 @@ package migrations
 import (
 	"WbDemoProject/Internal/repository"
 	"context"
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey - ключ advisory lock, чтобы два экземпляра не мигрировали базу одновременно
+const lockKey = 7_100_001
+
+// ErrSchemaOutdated - схема базы не совпадает с версией, которую ожидает сервис
+var ErrSchemaOutdated = errors.New("database schema is not up to date")
+
+// fileRegexp - имя файла миграции: 0001_name.up.sql или 0001_name.down.sql
+var fileRegexp = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration - версия схемы с SQL для применения и отката
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status - состояние миграции в базе
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	// Unknown - версия применена в базе, но в сервисе такой миграции нет
+	Unknown bool
+}
+
 type Migrations struct {
-	repo *repository.Repository
-}
-
-func New(repo *repository.Repository) *Migrations {
-	return &Migrations{repo: repo}
-}
-
-func (m *Migrations) InitTables(ctx context.Context) error {
-	query := `
-CREATE TABLE IF NOT EXISTS orders (
-    order_uid VARCHAR(50) PRIMARY KEY,
-    track_number VARCHAR(50),
-    entry VARCHAR(20),
-    locale VARCHAR(10),
-    internal_signature VARCHAR(100),
-    customer_id VARCHAR(50),
-    delivery_service VARCHAR(50),
-    shardkey VARCHAR(10),
-    sm_id INT,
-    date_created TIMESTAMP,
-    oof_shard VARCHAR(10)
-);
-
-CREATE TABLE IF NOT EXISTS delivery (
-    order_uid VARCHAR(50) PRIMARY KEY REFERENCES orders(order_uid) ON DELETE CASCADE,
-    name VARCHAR(100),
-    phone VARCHAR(20),
-    zip VARCHAR(20),
-    city VARCHAR(50),
-    address VARCHAR(100),
-    region VARCHAR(50),
-    email VARCHAR(50)
-);
-
-CREATE TABLE IF NOT EXISTS payment (
-    order_uid VARCHAR(50) PRIMARY KEY REFERENCES orders(order_uid) ON DELETE CASCADE,
-    transaction VARCHAR(50),
-    request_id VARCHAR(50),
-    currency VARCHAR(10),
-    provider VARCHAR(50),
-    amount INT,
-    payment_dt BIGINT,
-    bank VARCHAR(50),
-    delivery_cost INT,
-    goods_total INT,
-    custom_fee INT
-);
-
-CREATE TABLE IF NOT EXISTS items (
-    id SERIAL PRIMARY KEY,
-    order_uid VARCHAR(50) REFERENCES orders(order_uid) ON DELETE CASCADE,
-    chrt_id BIGINT,
-    track_number VARCHAR(50),
-    price INT,
-    rid VARCHAR(50),
-    name VARCHAR(100),
-    sale INT,
-    size VARCHAR(10),
-    total_price INT,
-    nm_id BIGINT,
-    brand VARCHAR(50),
-    status INT
-);
-`
-	maxRetries := 5
-	retryDelay := 5 * time.Second
+	repo       *repository.Repository
+	migrations []Migration
+}
+
+func New(repo *repository.Repository) (*Migrations, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrations{repo: repo, migrations: migrations}, nil
+}
+
+// Load - читаем миграции из sql/*.sql, у каждой версии должны быть up и down
+func Load(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "sql/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, p := range paths {
+		match := fileRegexp.FindStringSubmatch(path.Base(p))
+		if match == nil {
+			return nil, fmt.Errorf("migrations: invalid file name %q", p)
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		if version <= 0 {
+			return nil, fmt.Errorf("migrations: invalid version in %q", p)
+		}
+
+		body, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("migrations: %w", err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrations: version %d has different names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migrations: version %d must have both up and down files", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+
+	return migrations, nil
+}
+
+// WaitForDB - ждем, пока база поднимется (в docker compose она стартует позже сервиса)
+func (m *Migrations) WaitForDB(ctx context.Context, maxRetries int, retryDelay time.Duration) error {
+	var err error
 
 	for i := 0; i < maxRetries; i++ {
-		_, err := m.repo.DB.Exec(ctx, query)
-		if err == nil {
-			// Всё ок, таблицы созданы
+		if err = m.repo.Ping(ctx); err == nil {
 			return nil
 		}
 
-		fmt.Printf("Не удалось создать таблицы (попытка %d/%d): %v\n", i+1, maxRetries, err)
-		time.Sleep(retryDelay)
+		log.Printf("База недоступна (попытка %d/%d): %v", i+1, maxRetries, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay):
+		}
+	}
+
+	return fmt.Errorf("migrations: database unavailable after %d attempts: %w", maxRetries, err)
+}
+
+// Up - применяем все неприменённые миграции по порядку, каждую в своей транзакции
+func (m *Migrations) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+
+	for _, migration := range m.migrations {
+		ok, err := m.apply(ctx, migration)
+		if err != nil {
+			return applied, err
+		}
+
+		if ok {
+			applied = append(applied, migration)
+		}
+	}
+
+	return applied, nil
+}
+
+// Down - откатываем последнюю примененную миграцию
+func (m *Migrations) Down(ctx context.Context) (*Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	tx, err := m.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var version int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: error reading current version: %v", err)
+	}
+
+	if version == 0 {
+		return nil, nil
+	}
+
+	migration, ok := m.find(version)
+	if !ok {
+		return nil, fmt.Errorf("migrations: applied version %d is unknown to this build", version)
+	}
+
+	if _, err = tx.Exec(ctx, migration.Down); err != nil {
+		return nil, fmt.Errorf("migrations: error rolling back %04d_%s: %v", migration.Version, migration.Name, err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, version); err != nil {
+		return nil, fmt.Errorf("migrations: error removing version %d: %v", version, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("migrations: error committing rollback of version %d: %v", version, err)
+	}
+
+	return &migration, nil
+}
+
+// Redo - откатываем и заново применяем последнюю миграцию
+func (m *Migrations) Redo(ctx context.Context) (*Migration, error) {
+	migration, err := m.Down(ctx)
+	if err != nil || migration == nil {
+		return migration, err
+	}
+
+	if _, err = m.apply(ctx, *migration); err != nil {
+		return nil, err
+	}
+
+	return migration, nil
+}
+
+// Status - все известные миграции и примененные версии, которых нет в сервисе
+func (m *Migrations) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, appliedAt := range applied {
+		statuses = append(statuses, Status{Version: version, AppliedAt: &appliedAt, Unknown: true})
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int { return a.Version - b.Version })
+
+	return statuses, nil
+}
+
+// CheckVersion - сервис стартует только на базе, к которой применены ровно его миграции
+func (m *Migrations) CheckVersion(ctx context.Context) error {
+	var exists bool
+	err := m.repo.DB.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("migrations: error checking schema version: %v", err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: no migrations applied, run `migrate up`", ErrSchemaOutdated)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			return fmt.Errorf("%w: version %d is newer than this build", ErrSchemaOutdated, status.Version)
+		case status.AppliedAt == nil:
+			return fmt.Errorf("%w: version %04d_%s is not applied, run `migrate up`", ErrSchemaOutdated, status.Version, status.Name)
+		}
+	}
+
+	return nil
+}
+
+// apply - применяем миграцию, если ее еще нет в schema_migrations
+func (m *Migrations) apply(ctx context.Context, migration Migration) (bool, error) {
+	tx, err := m.begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var done bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, migration.Version).Scan(&done)
+	if err != nil {
+		return false, fmt.Errorf("migrations: error checking version %d: %v", migration.Version, err)
+	}
+
+	if done {
+		return false, nil
+	}
+
+	if _, err = tx.Exec(ctx, migration.Up); err != nil {
+		return false, fmt.Errorf("migrations: error applying %04d_%s: %v", migration.Version, migration.Name, err)
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+	if err != nil {
+		return false, fmt.Errorf("migrations: error recording version %d: %v", migration.Version, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("migrations: error committing version %d: %v", migration.Version, err)
+	}
+
+	return true, nil
+}
+
+// begin - транзакция под advisory lock, который снимается вместе с ней
+func (m *Migrations) begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := m.repo.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: error starting transaction: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		tx.Rollback(ctx)
+		return nil, fmt.Errorf("migrations: error taking lock: %v", err)
+	}
+
+	return tx, nil
+}
+
+func (m *Migrations) ensureTable(ctx context.Context) error {
+	_, err := m.repo.DB.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+)`)
+	if err != nil {
+		return fmt.Errorf("migrations: error creating schema_migrations: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Migrations) applied(ctx context.Context) (map[int]time.Time, error) {
+	rows, err := m.repo.DB.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("migrations: error reading applied versions: %v", err)
+	}
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("migrations: error scanning applied version: %v", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("migrations: error reading applied versions: %v", err)
+	}
+
+	return applied, nil
+}
+
+func (m *Migrations) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
 	}
 
-	return fmt.Errorf("не удалось создать таблицы после %d попыток", maxRetries)
+	return Migration{}, false
 }
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := Load(files)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d, versions must be sequential", i, m.Version)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		wantErr  string
+		wantVers []int
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"sql/0002_b.up.sql":   file("B"),
+				"sql/0002_b.down.sql": file("-B"),
+				"sql/0001_a.up.sql":   file("A"),
+				"sql/0001_a.down.sql": file("-A"),
+			},
+			wantVers: []int{1, 2},
+		},
+		{
+			name:    "missing down",
+			fsys:    fstest.MapFS{"sql/0001_a.up.sql": file("A")},
+			wantErr: "both up and down",
+		},
+		{
+			name:    "bad name",
+			fsys:    fstest.MapFS{"sql/init.sql": file("A")},
+			wantErr: "invalid file name",
+		},
+		{
+			name: "name mismatch",
+			fsys: fstest.MapFS{
+				"sql/0001_a.up.sql":   file("A"),
+				"sql/0001_b.down.sql": file("-A"),
+			},
+			wantErr: "different names",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.fsys)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if len(migrations) != len(tt.wantVers) {
+				t.Fatalf("got %d migrations, want %d", len(migrations), len(tt.wantVers))
+			}
+			for i, m := range migrations {
+				if m.Version != tt.wantVers[i] {
+					t.Errorf("migrations[%d].Version = %d, want %d", i, m.Version, tt.wantVers[i])
+				}
+			}
+		})
+	}
+}
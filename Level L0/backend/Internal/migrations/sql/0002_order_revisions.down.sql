@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS order_revisions;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS payload_hash;
+ALTER TABLE orders DROP COLUMN IF EXISTS version;
@@ -0,0 +1,13 @@
+-- версия заказа и история изменений
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payload_hash VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS order_revisions (
+    order_uid VARCHAR(50) REFERENCES orders(order_uid) ON DELETE CASCADE,
+    version INT NOT NULL,
+    payload_hash VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (order_uid, version)
+);
@@ -0,0 +1,6 @@
+-- товары и доставка читаются по order_uid при каждой выборке заказа.
+-- delivery.order_uid - первичный ключ, уникальный индекс по нему уже есть, отдельный не создаем
+CREATE INDEX IF NOT EXISTS items_order_uid_idx ON items (order_uid);
+
+-- список заказов и прогрев кэша идут по (date_created, order_uid) от новых к старым
+CREATE INDEX IF NOT EXISTS orders_date_created_idx ON orders (date_created DESC, order_uid DESC);
@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"WbDemoProject/Internal/entity"
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher - отправка события во внешний брокер
+type Publisher interface {
+	Publish(ctx context.Context, event entity.OrderEvent) error
+}
+
+// KafkaPublisher - публикация событий в топик Kafka, ключ сообщения - order_uid,
+// чтобы события одного заказа попадали в одну партицию по порядку
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaPublisher - конструктор
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{writer: &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event entity.OrderEvent) error {
+	err := p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.OrderUID),
+		Value: event.Payload,
+		Headers: []kafka.Header{
+			{Key: "event-type", Value: []byte(event.Type)},
+			{Key: "event-id", Value: []byte(strconv.FormatInt(event.ID, 10))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("outbox: error publishing event %d: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// MemoryPublisher - публикация в память для тестов
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []entity.OrderEvent
+	// Fail - если задана и возвращает ошибку, событие не публикуется
+	Fail func(event entity.OrderEvent) error
+}
+
+// NewMemoryPublisher - конструктор
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event entity.OrderEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Fail != nil {
+		if err := p.Fail(event); err != nil {
+			return err
+		}
+	}
+
+	p.events = append(p.events, event)
+
+	return nil
+}
+
+// Events - копия опубликованных событий
+func (p *MemoryPublisher) Events() []entity.OrderEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]entity.OrderEvent(nil), p.events...)
+}
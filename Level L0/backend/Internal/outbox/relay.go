@@ -0,0 +1,111 @@
+package outbox
+
+import (
+	"WbDemoProject/Internal/entity"
+	"context"
+	"log"
+	"time"
+)
+
+// Store - хранилище outbox
+type Store interface {
+	FetchOutbox(ctx context.Context, limit int) ([]entity.OrderEvent, error)
+	MarkOutboxPublished(ctx context.Context, id int64) error
+	MarkOutboxFailed(ctx context.Context, id int64, reason string) error
+}
+
+// Options - настройки relay
+type Options struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxBackoff - максимальная пауза между повторами после ошибок публикации
+	MaxBackoff time.Duration
+}
+
+// Relay - переносит события из outbox в брокер с гарантией at-least-once:
+// событие помечается опубликованным только после успешной отправки
+type Relay struct {
+	store     Store
+	publisher Publisher
+	opts      Options
+}
+
+// NewRelay - конструктор
+func NewRelay(store Store, publisher Publisher, opts Options) *Relay {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+
+	return &Relay{store: store, publisher: publisher, opts: opts}
+}
+
+// Run - публикуем события, пока не отменят ctx
+func (r *Relay) Run(ctx context.Context) {
+	failures := 0
+
+	for {
+		published, err := r.PublishBatch(ctx)
+		if err != nil {
+			failures++
+			log.Printf("outbox relay: %v", err)
+		} else {
+			failures = 0
+		}
+
+		// полная пачка - сразу берем следующую, при ошибках ждем все дольше
+		wait := r.opts.PollInterval
+		switch {
+		case failures > 0:
+			wait = r.backoff(failures)
+		case published == r.opts.BatchSize:
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// PublishBatch - публикуем одну пачку событий по порядку.
+// На первой ошибке останавливаемся, чтобы не нарушить порядок событий
+func (r *Relay) PublishBatch(ctx context.Context) (int, error) {
+	events, err := r.store.FetchOutbox(ctx, r.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, event := range events {
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			if markErr := r.store.MarkOutboxFailed(context.WithoutCancel(ctx), event.ID, err.Error()); markErr != nil {
+				log.Printf("outbox relay: %v", markErr)
+			}
+
+			return i, err
+		}
+
+		// если пометить не удалось, событие уйдет повторно - это допустимо для at-least-once
+		if err := r.store.MarkOutboxPublished(context.WithoutCancel(ctx), event.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(events), nil
+}
+
+func (r *Relay) backoff(failures int) time.Duration {
+	wait := r.opts.PollInterval
+	for i := 1; i < failures && wait < r.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, r.opts.MaxBackoff)
+}
@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/repository/memory"
+	"WbDemoProject/Internal/testutil"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPublishBatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		failOn        string
+		wantPublished []string
+		wantPending   int
+		wantErr       bool
+	}{
+		{name: "all published", wantPublished: []string{"a", "b", "c"}},
+		{name: "stops on first failure", failOn: "b", wantPublished: []string{"a"}, wantPending: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := memory.New()
+			for _, uid := range []string{"a", "b", "c"} {
+				if _, err := store.SaveOrderInDB(ctx, testutil.ValidOrder(uid)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			publisher := NewMemoryPublisher()
+			publisher.Fail = func(event entity.OrderEvent) error {
+				if event.OrderUID == tt.failOn {
+					return errors.New("broker unavailable")
+				}
+				return nil
+			}
+
+			relay := NewRelay(store, publisher, Options{BatchSize: 10})
+
+			_, err := relay.PublishBatch(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PublishBatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var published []string
+			for _, event := range publisher.Events() {
+				published = append(published, event.OrderUID)
+			}
+			if len(published) != len(tt.wantPublished) {
+				t.Fatalf("published = %v, want %v", published, tt.wantPublished)
+			}
+			for i := range published {
+				if published[i] != tt.wantPublished[i] {
+					t.Errorf("published = %v, want %v", published, tt.wantPublished)
+				}
+			}
+
+			pending, _ := store.FetchOutbox(ctx, 10)
+			if len(pending) != tt.wantPending {
+				t.Errorf("pending = %d, want %d", len(pending), tt.wantPending)
+			}
+			if tt.wantErr && pending[0].Attempts != 1 {
+				t.Errorf("failed event attempts = %d, want 1", pending[0].Attempts)
+			}
+		})
+	}
+}
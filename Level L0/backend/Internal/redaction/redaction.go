@@ -0,0 +1,260 @@
+package redaction
+
+import (
+	"WbDemoProject/Internal/auth"
+	"WbDemoProject/Internal/cache"
+	"WbDemoProject/Internal/entity"
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Action - что делать с полем
+type Action int
+
+const (
+	Show Action = iota
+	Mask
+	Hide
+)
+
+// Field - чувствительное поле заказа
+type Field string
+
+const (
+	DeliveryName       Field = "delivery.name"
+	DeliveryPhone      Field = "delivery.phone"
+	DeliveryEmail      Field = "delivery.email"
+	DeliveryAddress    Field = "delivery.address"
+	DeliveryZip        Field = "delivery.zip"
+	PaymentTransaction Field = "payment.transaction"
+	PaymentRequestID   Field = "payment.request_id"
+	PaymentBank        Field = "payment.bank"
+	PaymentAmounts     Field = "payment.amounts"
+	InternalSignature  Field = "internal_signature"
+)
+
+// Policy - правила видимости полей для роли, не указанные поля показываются как есть
+type Policy map[Field]Action
+
+// Policies - правила по ролям
+type Policies map[auth.Role]Policy
+
+// DefaultPolicies - поддержка видит маскированные контакты без платежных данных,
+// финансы видят оплату, но не адрес и контакты, админ видит все
+func DefaultPolicies() Policies {
+	return Policies{
+		auth.RoleAdmin: {},
+		auth.RoleSupport: {
+			DeliveryPhone:      Mask,
+			DeliveryEmail:      Mask,
+			PaymentTransaction: Hide,
+			PaymentRequestID:   Hide,
+			PaymentBank:        Hide,
+			InternalSignature:  Hide,
+		},
+		auth.RoleFinance: {
+			DeliveryName:      Mask,
+			DeliveryPhone:     Hide,
+			DeliveryEmail:     Hide,
+			DeliveryAddress:   Hide,
+			DeliveryZip:       Hide,
+			InternalSignature: Hide,
+		},
+	}
+}
+
+// Redactor - применяет политику роли к заказам перед отдачей клиенту
+type Redactor struct {
+	policies Policies
+}
+
+// New - конструктор
+func New(policies Policies) *Redactor {
+	return &Redactor{policies: policies}
+}
+
+// Order - копия заказа с примененной политикой роли. Для неизвестной роли скрывается все чувствительное
+func (r *Redactor) Order(order *entity.Order, role auth.Role) *entity.Order {
+	if order == nil {
+		return nil
+	}
+
+	policy, ok := r.policies[role]
+	if !ok {
+		policy = hideAll
+	}
+
+	redacted := *order
+	d, p := &redacted.Delivery, &redacted.Payment
+
+	d.Name = apply(policy[DeliveryName], d.Name, maskName)
+	d.Phone = apply(policy[DeliveryPhone], d.Phone, maskPhone)
+	d.Email = apply(policy[DeliveryEmail], d.Email, maskEmail)
+	d.Address = apply(policy[DeliveryAddress], d.Address, maskTail)
+	d.Zip = apply(policy[DeliveryZip], d.Zip, maskTail)
+	p.Transaction = apply(policy[PaymentTransaction], p.Transaction, maskTail)
+	p.RequestID = apply(policy[PaymentRequestID], p.RequestID, maskTail)
+	p.Bank = apply(policy[PaymentBank], p.Bank, maskTail)
+	redacted.InternalSignature = apply(policy[InternalSignature], redacted.InternalSignature, maskTail)
+
+	if policy[PaymentAmounts] == Hide {
+		p.Amount, p.DeliveryCost, p.GoodsTotal, p.CustomFee = 0, 0, 0, 0
+	}
+
+	return &redacted
+}
+
+var hideAll = Policy{
+	DeliveryName:       Hide,
+	DeliveryPhone:      Hide,
+	DeliveryEmail:      Hide,
+	DeliveryAddress:    Hide,
+	DeliveryZip:        Hide,
+	PaymentTransaction: Hide,
+	PaymentRequestID:   Hide,
+	PaymentBank:        Hide,
+	PaymentAmounts:     Hide,
+	InternalSignature:  Hide,
+}
+
+func apply(action Action, value string, mask func(string) string) string {
+	switch action {
+	case Hide:
+		return ""
+	case Mask:
+		return mask(value)
+	default:
+		return value
+	}
+}
+
+// maskPhone - +79991234567 -> +7******4567. Короткие номера, сохраненные до валидации,
+// тоже маскируются: код страны оставляем, только пока до последних 4 цифр остается что скрыть
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return strings.Repeat("*", len(phone))
+	}
+
+	prefix := 0
+	if strings.HasPrefix(phone, "+") {
+		prefix = min(2, len(phone)-5)
+	}
+
+	return phone[:prefix] + strings.Repeat("*", len(phone)-prefix-4) + phone[len(phone)-4:]
+}
+
+// maskEmail - test@gmail.com -> t***@gmail.com, первый символ берем целой руной
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return maskTail(email)
+	}
+
+	_, size := utf8.DecodeRuneInString(local)
+
+	return local[:size] + strings.Repeat("*", max(utf8.RuneCountInString(local)-1, 3)) + "@" + domain
+}
+
+// maskName - Test Testov -> T*** T*****
+func maskName(name string) string {
+	parts := strings.Fields(name)
+	for i, part := range parts {
+		runes := []rune(part)
+		parts[i] = string(runes[:1]) + strings.Repeat("*", len(runes)-1)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// maskTail - оставляем только первые два символа
+func maskTail(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 2 {
+		return strings.Repeat("*", len(runes))
+	}
+
+	return string(runes[:2]) + strings.Repeat("*", len(runes)-2)
+}
+
+// Orders - сервис заказов, который оборачивает Service
+type Orders interface {
+	GetOrder(ctx context.Context, orderUID string) (*entity.Order, error)
+	GetOrderHistory(ctx context.Context, orderUID string) ([]entity.OrderRevision, error)
+	ListOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderPage, error)
+	ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error
+	OrderStats(ctx context.Context, filter entity.StatsFilter) (*entity.OrderStats, error)
+	CacheStats() cache.Stats
+}
+
+// Service - сервис заказов для API: каждый заказ, который он отдает, уже прошел политику роли
+// из контекста запроса. Обработчики работают только с ним, поэтому забыть скрыть поля нельзя,
+// а запрос без роли получает заказ со всем чувствительным скрытым
+type Service struct {
+	orders   Orders
+	redactor *Redactor
+}
+
+// NewService - конструктор
+func NewService(orders Orders, redactor *Redactor) *Service {
+	return &Service{orders: orders, redactor: redactor}
+}
+
+// redact - заказ по роли из ctx
+func (s *Service) redact(ctx context.Context, order *entity.Order) *entity.Order {
+	role, _ := auth.RoleFromContext(ctx)
+
+	return s.redactor.Order(order, role)
+}
+
+func (s *Service) GetOrder(ctx context.Context, orderUID string) (*entity.Order, error) {
+	order, err := s.orders.GetOrder(ctx, orderUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.redact(ctx, order), nil
+}
+
+func (s *Service) GetOrderHistory(ctx context.Context, orderUID string) ([]entity.OrderRevision, error) {
+	revisions, err := s.orders.GetOrderHistory(ctx, orderUID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range revisions {
+		revisions[i].Order = *s.redact(ctx, &revisions[i].Order)
+	}
+
+	return revisions, nil
+}
+
+func (s *Service) ListOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderPage, error) {
+	page, err := s.orders.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, order := range page.Orders {
+		page.Orders[i] = s.redact(ctx, order)
+	}
+
+	return page, nil
+}
+
+func (s *Service) ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error {
+	return s.orders.ExportOrders(ctx, from, to, func(order *entity.Order) error {
+		return fn(s.redact(ctx, order))
+	})
+}
+
+// OrderStats - агрегаты без персональных данных, отдаются как есть
+func (s *Service) OrderStats(ctx context.Context, filter entity.StatsFilter) (*entity.OrderStats, error) {
+	return s.orders.OrderStats(ctx, filter)
+}
+
+// CacheStats - счетчики кэша, отдаются как есть
+func (s *Service) CacheStats() cache.Stats {
+	return s.orders.CacheStats()
+}
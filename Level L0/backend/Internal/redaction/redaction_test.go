@@ -0,0 +1,307 @@
+package redaction
+
+import (
+	"WbDemoProject/Internal/auth"
+	"WbDemoProject/Internal/cache"
+	"WbDemoProject/Internal/entity"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaskPhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{phone: "+79991234567", want: "+7******4567"},
+		{phone: "89991234567", want: "*******4567"},
+		{phone: "+12345", want: "+*2345"},
+		{phone: "+1234", want: "*1234"},
+		{phone: "12345", want: "*2345"},
+		{phone: "1234", want: "****"},
+		{phone: "+1", want: "**"},
+		{phone: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			if got := maskPhone(tt.phone); got != tt.want {
+				t.Errorf("maskPhone(%q) = %q, want %q", tt.phone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "test@gmail.com", want: "t***@gmail.com"},
+		{email: "a@gmail.com", want: "a***@gmail.com"},
+		{email: "verylong@mail.ru", want: "v*******@mail.ru"},
+		{email: "иван@почта.рф", want: "и***@почта.рф"},
+		{email: "ёж@mail.ru", want: "ё***@mail.ru"},
+		{email: "@mail.ru", want: "@m******"},
+		{email: "broken", want: "br****"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got := maskEmail(tt.email)
+			if got != tt.want {
+				t.Errorf("maskEmail(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("maskEmail(%q) = %q, not valid UTF-8", tt.email, got)
+			}
+		})
+	}
+}
+
+func testOrder() *entity.Order {
+	return &entity.Order{
+		OrderUID:          "b563feb7b2b84b6test",
+		TrackNumber:       "WBILMTESTTRACK",
+		InternalSignature: "signature",
+		Delivery: entity.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+79720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: entity.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			RequestID:    "req-1",
+			Currency:     "USD",
+			Amount:       1817,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+			CustomFee:    10,
+		},
+	}
+}
+
+func TestRedactorOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		role auth.Role
+		want func(o *entity.Order)
+	}{
+		{
+			name: "admin sees everything",
+			role: auth.RoleAdmin,
+			want: func(o *entity.Order) {},
+		},
+		{
+			name: "support sees masked contacts without payment details",
+			role: auth.RoleSupport,
+			want: func(o *entity.Order) {
+				o.Delivery.Phone = "+7******0000"
+				o.Delivery.Email = "t***@gmail.com"
+				o.Payment.Transaction, o.Payment.RequestID, o.Payment.Bank = "", "", ""
+				o.InternalSignature = ""
+			},
+		},
+		{
+			name: "finance sees payment without address and contacts",
+			role: auth.RoleFinance,
+			want: func(o *entity.Order) {
+				o.Delivery.Name = "T*** T*****"
+				o.Delivery.Phone, o.Delivery.Email, o.Delivery.Address, o.Delivery.Zip = "", "", "", ""
+				o.InternalSignature = ""
+			},
+		},
+		{
+			name: "unknown role sees nothing sensitive",
+			role: auth.Role("guest"),
+			want: func(o *entity.Order) {
+				o.Delivery.Name, o.Delivery.Phone, o.Delivery.Email, o.Delivery.Address, o.Delivery.Zip = "", "", "", "", ""
+				o.Payment.Transaction, o.Payment.RequestID, o.Payment.Bank = "", "", ""
+				o.Payment.Amount, o.Payment.DeliveryCost, o.Payment.GoodsTotal, o.Payment.CustomFee = 0, 0, 0, 0
+				o.InternalSignature = ""
+			},
+		},
+		{
+			name: "no role sees nothing sensitive",
+			role: "",
+			want: func(o *entity.Order) {
+				o.Delivery.Name, o.Delivery.Phone, o.Delivery.Email, o.Delivery.Address, o.Delivery.Zip = "", "", "", "", ""
+				o.Payment.Transaction, o.Payment.RequestID, o.Payment.Bank = "", "", ""
+				o.Payment.Amount, o.Payment.DeliveryCost, o.Payment.GoodsTotal, o.Payment.CustomFee = 0, 0, 0, 0
+				o.InternalSignature = ""
+			},
+		},
+	}
+
+	redactor := New(DefaultPolicies())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testOrder()
+			want := testOrder()
+			tt.want(want)
+
+			got := redactor.Order(order, tt.role)
+			assertOrder(t, got, want)
+
+			// исходный заказ (например, из кэша) не меняется
+			assertOrder(t, order, testOrder())
+		})
+	}
+}
+
+// fakeOrders - сервис заказов, который отдает один и тот же заказ из всех методов
+type fakeOrders struct{}
+
+func (fakeOrders) GetOrder(_ context.Context, _ string) (*entity.Order, error) {
+	return testOrder(), nil
+}
+
+func (fakeOrders) GetOrderHistory(_ context.Context, _ string) ([]entity.OrderRevision, error) {
+	return []entity.OrderRevision{{Version: 1, Order: *testOrder()}, {Version: 2, Order: *testOrder()}}, nil
+}
+
+func (fakeOrders) ListOrders(_ context.Context, _ entity.OrderFilter) (*entity.OrderPage, error) {
+	return &entity.OrderPage{Orders: []*entity.Order{testOrder(), testOrder()}}, nil
+}
+
+func (fakeOrders) ExportOrders(_ context.Context, _, _ time.Time, fn func(order *entity.Order) error) error {
+	for range 2 {
+		if err := fn(testOrder()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fakeOrders) OrderStats(_ context.Context, _ entity.StatsFilter) (*entity.OrderStats, error) {
+	return &entity.OrderStats{}, nil
+}
+
+func (fakeOrders) CacheStats() cache.Stats {
+	return cache.Stats{}
+}
+
+// roleContext - контекст запроса, прошедшего auth.Middleware с ключом роли
+func roleContext(t *testing.T, role auth.Role) context.Context {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set(auth.APIKeyHeader, "key")
+
+	auth.Middleware(auth.Keys{"key": role})(ctx)
+	if ctx.IsAborted() {
+		t.Fatalf("auth middleware rejected role %q", role)
+	}
+
+	return ctx.Request.Context()
+}
+
+func TestServiceRedacts(t *testing.T) {
+	service := NewService(fakeOrders{}, New(DefaultPolicies()))
+
+	tests := []struct {
+		name string
+		get  func(ctx context.Context) ([]*entity.Order, error)
+	}{
+		{
+			name: "GetOrder",
+			get: func(ctx context.Context) ([]*entity.Order, error) {
+				order, err := service.GetOrder(ctx, "uid")
+				return []*entity.Order{order}, err
+			},
+		},
+		{
+			name: "GetOrderHistory",
+			get: func(ctx context.Context) ([]*entity.Order, error) {
+				revisions, err := service.GetOrderHistory(ctx, "uid")
+				orders := make([]*entity.Order, 0, len(revisions))
+				for i := range revisions {
+					orders = append(orders, &revisions[i].Order)
+				}
+				return orders, err
+			},
+		},
+		{
+			name: "ListOrders",
+			get: func(ctx context.Context) ([]*entity.Order, error) {
+				page, err := service.ListOrders(ctx, entity.OrderFilter{})
+				if err != nil {
+					return nil, err
+				}
+				return page.Orders, nil
+			},
+		},
+		{
+			name: "ExportOrders",
+			get: func(ctx context.Context) ([]*entity.Order, error) {
+				var orders []*entity.Order
+				err := service.ExportOrders(ctx, time.Time{}, time.Now(), func(order *entity.Order) error {
+					orders = append(orders, order)
+					return nil
+				})
+				return orders, err
+			},
+		},
+	}
+
+	contexts := []struct {
+		name string
+		ctx  context.Context
+		role auth.Role
+	}{
+		{name: "support", ctx: roleContext(t, auth.RoleSupport), role: auth.RoleSupport},
+		{name: "finance", ctx: roleContext(t, auth.RoleFinance), role: auth.RoleFinance},
+		{name: "no role", ctx: context.Background(), role: ""},
+	}
+
+	for _, tt := range tests {
+		for _, c := range contexts {
+			t.Run(tt.name+"/"+c.name, func(t *testing.T) {
+				orders, err := tt.get(c.ctx)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(orders) == 0 {
+					t.Fatal("no orders returned")
+				}
+
+				want := New(DefaultPolicies()).Order(testOrder(), c.role)
+				for _, got := range orders {
+					assertOrder(t, got, want)
+				}
+			})
+		}
+	}
+}
+
+func assertOrder(t *testing.T, got, want *entity.Order) {
+	t.Helper()
+
+	if got.Delivery != want.Delivery {
+		t.Errorf("delivery = %+v, want %+v", got.Delivery, want.Delivery)
+	}
+	if got.Payment != want.Payment {
+		t.Errorf("payment = %+v, want %+v", got.Payment, want.Payment)
+	}
+	if got.InternalSignature != want.InternalSignature {
+		t.Errorf("internal_signature = %q, want %q", got.InternalSignature, want.InternalSignature)
+	}
+	if got.OrderUID != want.OrderUID || got.TrackNumber != want.TrackNumber {
+		t.Errorf("order identity changed: %q/%q", got.OrderUID, got.TrackNumber)
+	}
+}
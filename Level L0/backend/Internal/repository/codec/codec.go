@@ -0,0 +1,54 @@
+// Package codec - общие для всех хранилищ форматы: json заказа с хэшем и курсор страниц.
+// Без зависимостей от драйверов БД, чтобы in-memory хранилище не тянуло pgx
+package codec
+
+import (
+	"WbDemoProject/Internal/entity"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// OrderPayload - json заказа и его sha256 для поиска дубликатов
+func OrderPayload(order *entity.Order) ([]byte, string, error) {
+	normalized := *order
+	normalized.DateCreated = normalized.DateCreated.UTC()
+
+	payload, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sum := sha256.Sum256(payload)
+
+	return payload, hex.EncodeToString(sum[:]), nil
+}
+
+// EncodeCursor - курсор это дата создания и uid последнего заказа на странице
+func EncodeCursor(createdAt time.Time, orderUID string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + orderUID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor - разбираем курсор из EncodeCursor
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", entity.ErrInvalidCursor
+	}
+
+	createdAt, orderUID, ok := strings.Cut(string(raw), "|")
+	if !ok || orderUID == "" {
+		return time.Time{}, "", entity.ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", entity.ErrInvalidCursor
+	}
+
+	return t, orderUID, nil
+}
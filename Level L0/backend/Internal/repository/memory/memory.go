@@ -0,0 +1,402 @@
+package memory
+
+import (
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/repository/codec"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+type storedOrder struct {
+	order     entity.Order
+	hash      string
+	version   int
+	revisions []entity.OrderRevision
+}
+
+// Store - хранилище заказов в памяти с той же семантикой, что и Postgres репозиторий.
+// Используется в тестах и для запуска без базы
+type Store struct {
+	mu     sync.RWMutex
+	orders map[string]*storedOrder
+	outbox []entity.OrderEvent
+	nextID int64
+	now    func() time.Time
+}
+
+// New - конструктор
+func New() *Store {
+	return &Store{
+		orders: make(map[string]*storedOrder),
+		now:    time.Now,
+	}
+}
+
+// SaveOrderInDB - идемпотентное сохранение: повтор любой сохраненной версии подтверждается, измененный заказ получает новую версию
+func (s *Store) SaveOrderInDB(ctx context.Context, order *entity.Order) (entity.SaveStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	payload, hash, err := codec.OrderPayload(order)
+	if err != nil {
+		return 0, fmt.Errorf("MemoryStore: cannot marshal order: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.orders[order.OrderUID]
+	status := entity.OrderCreated
+
+	switch {
+	case !ok:
+		stored = &storedOrder{}
+		s.orders[order.OrderUID] = stored
+	// повторно пришедшая старая версия заказа не откатывает его, как и в Postgres
+	case slices.ContainsFunc(stored.revisions, func(r entity.OrderRevision) bool { return r.PayloadHash == hash }):
+		return entity.OrderUnchanged, nil
+	default:
+		status = entity.OrderUpdated
+	}
+
+	stored.order = cloneOrder(order)
+	stored.hash = hash
+	stored.version++
+	stored.revisions = append(stored.revisions, entity.OrderRevision{
+		Version:     stored.version,
+		PayloadHash: hash,
+		CreatedAt:   s.now(),
+		Order:       cloneOrder(order),
+	})
+
+	eventType := entity.EventOrderCreated
+	if status == entity.OrderUpdated {
+		eventType = entity.EventOrderUpdated
+	}
+
+	event, err := json.Marshal(entity.OrderEventPayload{
+		Type:       eventType,
+		OrderUID:   order.OrderUID,
+		Version:    stored.version,
+		OccurredAt: s.now().UTC(),
+		Order:      payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("MemoryStore: cannot marshal event: %v", err)
+	}
+
+	s.nextID++
+	s.outbox = append(s.outbox, entity.OrderEvent{
+		ID:        s.nextID,
+		Type:      eventType,
+		OrderUID:  order.OrderUID,
+		Payload:   event,
+		CreatedAt: s.now(),
+	})
+
+	return status, nil
+}
+
+// GetOrderFromDB - заказ по order_uid
+func (s *Store) GetOrderFromDB(_ context.Context, orderUID string) (*entity.Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.orders[orderUID]
+	if !ok {
+		return nil, fmt.Errorf("MemoryStore: %w", entity.ErrOrderNotFound)
+	}
+
+	order := cloneOrder(&stored.order)
+
+	return &order, nil
+}
+
+// GetAllOrdersFromDB - обход заказов от новых к старым
+func (s *Store) GetAllOrdersFromDB(ctx context.Context, limit int, fn func(order *entity.Order) error) error {
+	orders := s.sorted(func(*entity.Order) bool { return true })
+	if limit > 0 && len(orders) > limit {
+		orders = orders[:limit]
+	}
+
+	for _, order := range orders {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetOrderRevisions - история версий заказа
+func (s *Store) GetOrderRevisions(_ context.Context, orderUID string) ([]entity.OrderRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.orders[orderUID]
+	if !ok {
+		return nil, nil
+	}
+
+	return slices.Clone(stored.revisions), nil
+}
+
+// ListOrders - фильтрация и курсорная пагинация как в Postgres репозитории
+func (s *Store) ListOrders(_ context.Context, filter entity.OrderFilter) (*entity.OrderPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	var (
+		cursorCreated time.Time
+		cursorUID     string
+	)
+	if filter.Cursor != "" {
+		var err error
+		cursorCreated, cursorUID, err = codec.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	orders := s.sorted(func(order *entity.Order) bool {
+		if filter.Cursor != "" && !before(order, cursorCreated, cursorUID) {
+			return false
+		}
+
+		return matches(order, filter)
+	})
+
+	page := &entity.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = codec.EncodeCursor(last.DateCreated, last.OrderUID)
+	}
+
+	return page, nil
+}
+
+// ExportOrders - заказы за [from, to) от старых к новым
+func (s *Store) ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error {
+	orders := s.sorted(func(order *entity.Order) bool {
+		return !order.DateCreated.Before(from) && order.DateCreated.Before(to)
+	})
+	slices.Reverse(orders)
+
+	for _, order := range orders {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OrderStats - статистика за период с той же семантикой, что и SQL версия
+func (s *Store) OrderStats(_ context.Context, filter entity.StatsFilter) (*entity.OrderStats, error) {
+	orders := s.sorted(func(order *entity.Order) bool {
+		return (filter.From == nil || !order.DateCreated.Before(*filter.From)) &&
+			(filter.To == nil || order.DateCreated.Before(*filter.To))
+	})
+
+	stats := &entity.OrderStats{}
+	groups := make(map[entity.StatsDimension]map[string]*entity.StatsBucket)
+	add := func(dimension entity.StatsDimension, key string, gmv int64) *entity.StatsBucket {
+		if groups[dimension] == nil {
+			groups[dimension] = make(map[string]*entity.StatsBucket)
+		}
+
+		bucket, ok := groups[dimension][key]
+		if !ok {
+			bucket = &entity.StatsBucket{Key: key}
+			groups[dimension][key] = bucket
+		}
+		bucket.GMV += gmv
+
+		return bucket
+	}
+
+	items := 0
+	for _, order := range orders {
+		amount := int64(order.Payment.Amount)
+		stats.Orders++
+		stats.GMV += amount
+		items += len(order.Items)
+
+		add(entity.StatsByDay, order.DateCreated.Format(time.DateOnly), amount).Orders++
+		add(entity.StatsByDeliveryService, order.DeliveryService, amount).Orders++
+		add(entity.StatsByProvider, order.Payment.Provider, amount).Orders++
+		add(entity.StatsByCurrency, order.Payment.Currency, amount).Orders++
+
+		brands := make(map[string]bool)
+		for _, item := range order.Items {
+			bucket := add(entity.StatsByBrand, item.Brand, int64(item.TotalPrice))
+			if !brands[item.Brand] {
+				brands[item.Brand] = true
+				bucket.Orders++
+			}
+		}
+	}
+
+	if stats.Orders > 0 {
+		stats.AvgBasket = float64(stats.GMV) / float64(stats.Orders)
+		stats.AvgItems = float64(items) / float64(stats.Orders)
+	}
+
+	for _, dimension := range entity.StatsDimensions {
+		buckets := []entity.StatsBucket{}
+		for _, bucket := range groups[dimension] {
+			buckets = append(buckets, *bucket)
+		}
+
+		slices.SortFunc(buckets, func(a, b entity.StatsBucket) int {
+			if dimension != entity.StatsByDay && a.GMV != b.GMV {
+				if a.GMV > b.GMV {
+					return -1
+				}
+				return 1
+			}
+
+			return strings.Compare(a.Key, b.Key)
+		})
+
+		stats.SetBreakdown(dimension, buckets)
+	}
+
+	return stats, nil
+}
+
+// FetchOutbox - неопубликованные события по порядку
+func (s *Store) FetchOutbox(_ context.Context, limit int) ([]entity.OrderEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := min(limit, len(s.outbox))
+
+	return slices.Clone(s.outbox[:n]), nil
+}
+
+// MarkOutboxPublished - убираем событие из очереди
+func (s *Store) MarkOutboxPublished(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outbox = slices.DeleteFunc(s.outbox, func(event entity.OrderEvent) bool { return event.ID == id })
+
+	return nil
+}
+
+// MarkOutboxFailed - учитываем неудачную попытку
+func (s *Store) MarkOutboxFailed(_ context.Context, id int64, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.outbox {
+		if s.outbox[i].ID == id {
+			s.outbox[i].Attempts++
+		}
+	}
+
+	return nil
+}
+
+// sorted - копии подходящих заказов от новых к старым
+func (s *Store) sorted(keep func(order *entity.Order) bool) []*entity.Order {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orders := make([]*entity.Order, 0, len(s.orders))
+	for _, stored := range s.orders {
+		if keep(&stored.order) {
+			order := cloneOrder(&stored.order)
+			orders = append(orders, &order)
+		}
+	}
+
+	slices.SortFunc(orders, func(a, b *entity.Order) int {
+		if c := b.DateCreated.Compare(a.DateCreated); c != 0 {
+			return c
+		}
+
+		return strings.Compare(b.OrderUID, a.OrderUID)
+	})
+
+	return orders
+}
+
+// before - заказ идет после курсора в порядке (date_created, order_uid) DESC
+func before(order *entity.Order, createdAt time.Time, orderUID string) bool {
+	if c := order.DateCreated.Compare(createdAt); c != 0 {
+		return c < 0
+	}
+
+	return order.OrderUID < orderUID
+}
+
+func matches(order *entity.Order, filter entity.OrderFilter) bool {
+	switch {
+	case filter.CustomerID != "" && order.CustomerID != filter.CustomerID:
+		return false
+	case filter.DateFrom != nil && order.DateCreated.Before(*filter.DateFrom):
+		return false
+	case filter.DateTo != nil && !order.DateCreated.Before(*filter.DateTo):
+		return false
+	case filter.DeliveryService != "" && order.DeliveryService != filter.DeliveryService:
+		return false
+	case filter.Provider != "" && order.Payment.Provider != filter.Provider:
+		return false
+	case filter.Currency != "" && order.Payment.Currency != filter.Currency:
+		return false
+	}
+
+	if filter.Brand != "" && !slices.ContainsFunc(order.Items, func(item entity.Item) bool { return item.Brand == filter.Brand }) {
+		return false
+	}
+
+	if filter.NmID != 0 && !slices.ContainsFunc(order.Items, func(item entity.Item) bool { return item.NmID == filter.NmID }) {
+		return false
+	}
+
+	if filter.Query != "" {
+		query := strings.ToLower(filter.Query)
+		d := order.Delivery
+		if !strings.Contains(strings.ToLower(d.Name), query) &&
+			!strings.Contains(strings.ToLower(d.City), query) &&
+			!strings.Contains(strings.ToLower(d.Address), query) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func cloneOrder(order *entity.Order) entity.Order {
+	clone := *order
+	clone.Items = slices.Clone(order.Items)
+
+	return clone
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/testutil"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveOrderInDB(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	order := testutil.ValidOrder("order-1")
+	changed := testutil.ValidOrder("order-1")
+	changed.Delivery.City = "Moscow"
+
+	steps := []struct {
+		order       *entity.Order
+		wantStatus  entity.SaveStatus
+		wantVersion int
+	}{
+		{order, entity.OrderCreated, 1},
+		{order, entity.OrderUnchanged, 1},
+		{changed, entity.OrderUpdated, 2},
+		// повторная доставка старой версии не откатывает заказ и не пишет событие
+		{order, entity.OrderUnchanged, 2},
+	}
+
+	for _, step := range steps {
+		status, err := store.SaveOrderInDB(ctx, step.order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != step.wantStatus {
+			t.Errorf("status = %v, want %v", status, step.wantStatus)
+		}
+
+		revisions, _ := store.GetOrderRevisions(ctx, order.OrderUID)
+		if len(revisions) != step.wantVersion {
+			t.Errorf("revisions = %d, want %d", len(revisions), step.wantVersion)
+		}
+	}
+
+	events, _ := store.FetchOutbox(ctx, 10)
+	if len(events) != 2 || events[0].Type != entity.EventOrderCreated || events[1].Type != entity.EventOrderUpdated {
+		t.Errorf("outbox = %+v, want created and updated events", events)
+	}
+
+	stored, err := store.GetOrderFromDB(ctx, order.OrderUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Delivery.City != "Moscow" {
+		t.Errorf("city = %q, want the latest version Moscow", stored.Delivery.City)
+	}
+
+	if _, err := store.GetOrderFromDB(ctx, "missing"); !errors.Is(err, entity.ErrOrderNotFound) {
+		t.Errorf("GetOrderFromDB(missing) error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestListOrdersPagination(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, uid := range []string{"a", "b", "c", "d", "e"} {
+		order := testutil.ValidOrder(uid)
+		order.DateCreated = base.Add(time.Duration(i) * time.Hour)
+		if _, err := store.SaveOrderInDB(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	filter := entity.OrderFilter{Limit: 2}
+
+	for {
+		page, err := store.ListOrders(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, order := range page.Orders {
+			got = append(got, order.OrderUID)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	if want := "edcba"; strings.Join(got, "") != want {
+		t.Errorf("pages = %v, want %s", got, want)
+	}
+
+	if _, err := store.ListOrders(ctx, entity.OrderFilter{Cursor: "!!"}); !errors.Is(err, entity.ErrInvalidCursor) {
+		t.Errorf("bad cursor error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestOrderStats(t *testing.T) {
+	ctx := context.Background()
+	store := New()
+
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, uid := range []string{"a", "b", "c"} {
+		order := testutil.ValidOrder(uid)
+		order.DateCreated = base.Add(time.Duration(i) * 24 * time.Hour)
+		if uid == "c" {
+			order.Payment.Provider = "sbp"
+			order.Payment.Amount = 1000
+		}
+		if _, err := store.SaveOrderInDB(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	to := base.Add(48 * time.Hour)
+	tests := []struct {
+		name       string
+		filter     entity.StatsFilter
+		wantOrders int64
+		wantGMV    int64
+		wantDays   int
+		wantTop    string
+	}{
+		{name: "all orders", wantOrders: 3, wantGMV: 1817*2 + 1000, wantDays: 3, wantTop: "wbpay"},
+		{name: "period", filter: entity.StatsFilter{To: &to}, wantOrders: 2, wantGMV: 1817 * 2, wantDays: 2, wantTop: "wbpay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := store.OrderStats(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if stats.Orders != tt.wantOrders || stats.GMV != tt.wantGMV {
+				t.Errorf("orders = %d, gmv = %d, want %d and %d", stats.Orders, stats.GMV, tt.wantOrders, tt.wantGMV)
+			}
+			if len(stats.ByDay) != tt.wantDays {
+				t.Errorf("days = %d, want %d", len(stats.ByDay), tt.wantDays)
+			}
+			if stats.ByProvider[0].Key != tt.wantTop {
+				t.Errorf("top provider = %q, want %q", stats.ByProvider[0].Key, tt.wantTop)
+			}
+			if stats.AvgItems != 1 {
+				t.Errorf("avg items = %v, want 1", stats.AvgItems)
+			}
+			if len(stats.ByBrand) != 1 || stats.ByBrand[0].Orders != tt.wantOrders {
+				t.Errorf("by brand = %+v, want one brand in every order", stats.ByBrand)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/repository/codec"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// orderSelect - заказ вместе с доставкой, оплатой и товарами одной строкой
+const orderSelect = `
+	SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id,
+	       o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
+	       d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+	       p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt,
+	       p.bank, p.delivery_cost, p.goods_total, p.custom_fee,
+	       COALESCE((SELECT json_agg(i ORDER BY i.id) FROM items i WHERE i.order_uid = o.order_uid), '[]')
+	FROM orders o
+	JOIN delivery d ON d.order_uid = o.order_uid
+	JOIN payment p ON p.order_uid = o.order_uid`
+
+// ListOrders - страница заказов по фильтрам, от новых к старым
+func (repo *Repository) ListOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	var conditions []string
+	var args []any
+
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.CustomerID != "" {
+		conditions = append(conditions, "o.customer_id = "+arg(filter.CustomerID))
+	}
+	if filter.DateFrom != nil {
+		conditions = append(conditions, "o.date_created >= "+arg(*filter.DateFrom))
+	}
+	if filter.DateTo != nil {
+		conditions = append(conditions, "o.date_created < "+arg(*filter.DateTo))
+	}
+	if filter.DeliveryService != "" {
+		conditions = append(conditions, "o.delivery_service = "+arg(filter.DeliveryService))
+	}
+	if filter.Provider != "" {
+		conditions = append(conditions, "p.provider = "+arg(filter.Provider))
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "p.currency = "+arg(filter.Currency))
+	}
+	if filter.Brand != "" {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = "+arg(filter.Brand)+")")
+	}
+	if filter.NmID != 0 {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.nm_id = "+arg(filter.NmID)+")")
+	}
+	if filter.Query != "" {
+		pattern := arg("%" + escapeLike(filter.Query) + "%")
+		conditions = append(conditions,
+			fmt.Sprintf("(d.name ILIKE %[1]s OR d.city ILIKE %[1]s OR d.address ILIKE %[1]s)", pattern))
+	}
+	if filter.Cursor != "" {
+		createdAt, orderUID, err := codec.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(o.date_created, o.order_uid) < (%s, %s)", arg(createdAt), arg(orderUID)))
+	}
+
+	query := orderSelect
+	if len(conditions) > 0 {
+		query += "\n\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	// берем на одну запись больше, чтобы понять есть ли следующая страница
+	query += "\n\tORDER BY o.date_created DESC, o.order_uid DESC\n\tLIMIT " + arg(limit+1)
+
+	orders, err := repo.queryOrders(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.OrderPage{Orders: orders}
+	if page.Orders == nil {
+		page.Orders = []*entity.Order{}
+	}
+
+	if len(page.Orders) > limit {
+		page.Orders = page.Orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = codec.EncodeCursor(last.DateCreated, last.OrderUID)
+	}
+
+	return page, nil
+}
+
+// ExportOrders - построчно отдаем в fn заказы, созданные в [from, to), не собирая весь результат в памяти
+func (repo *Repository) ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error {
+	rows, err := repo.DB.Query(ctx, orderSelect+`
+	WHERE o.date_created >= $1 AND o.date_created < $2
+	ORDER BY o.date_created, o.order_uid`, from, to)
+	if err != nil {
+		return fmt.Errorf("PostgresRepository: error exporting orders: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(order); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("PostgresRepository: error reading exported orders: %v", err)
+	}
+
+	return nil
+}
+
+// scanOrder - разбираем строку из orderSelect
+func scanOrder(row pgx.Row) (*entity.Order, error) {
+	var order entity.Order
+	var items []byte
+
+	err := row.Scan(
+		&order.OrderUID,
+		&order.TrackNumber,
+		&order.Entry,
+		&order.Locale,
+		&order.InternalSignature,
+		&order.CustomerID,
+		&order.DeliveryService,
+		&order.ShardKey,
+		&order.SmID,
+		&order.DateCreated,
+		&order.OofShard,
+		&order.Delivery.Name,
+		&order.Delivery.Phone,
+		&order.Delivery.Zip,
+		&order.Delivery.City,
+		&order.Delivery.Address,
+		&order.Delivery.Region,
+		&order.Delivery.Email,
+		&order.Payment.Transaction,
+		&order.Payment.RequestID,
+		&order.Payment.Currency,
+		&order.Payment.Provider,
+		&order.Payment.Amount,
+		&order.Payment.PaymentDT,
+		&order.Payment.Bank,
+		&order.Payment.DeliveryCost,
+		&order.Payment.GoodsTotal,
+		&order.Payment.CustomFee,
+		&items,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error scanning order: %v", err)
+	}
+
+	if err = json.Unmarshal(items, &order.Items); err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error decoding items: %v", err)
+	}
+
+	return &order, nil
+}
+
+// escapeLike - экранируем спецсимволы LIKE в пользовательском запросе
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"WbDemoProject/Internal/entity"
+	"context"
+	"fmt"
+)
+
+// FetchOutbox - неопубликованные события в порядке записи
+func (repo *Repository) FetchOutbox(ctx context.Context, limit int) ([]entity.OrderEvent, error) {
+	rows, err := repo.DB.Query(ctx, `
+		SELECT id, event_type, order_uid, payload, attempts, created_at
+		FROM outbox
+		WHERE published_at IS NULL
+		ORDER BY id
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error fetching outbox: %v", err)
+	}
+	defer rows.Close()
+
+	var events []entity.OrderEvent
+
+	for rows.Next() {
+		var event entity.OrderEvent
+		err = rows.Scan(&event.ID, &event.Type, &event.OrderUID, &event.Payload, &event.Attempts, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("PostgresRepository: error scanning outbox event: %v", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error reading outbox: %v", err)
+	}
+
+	return events, nil
+}
+
+// MarkOutboxPublished - событие доставлено
+func (repo *Repository) MarkOutboxPublished(ctx context.Context, id int64) error {
+	_, err := repo.DB.Exec(ctx, `UPDATE outbox SET published_at = NOW(), last_error = NULL WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("PostgresRepository: error marking outbox event %d: %v", id, err)
+	}
+
+	return nil
+}
+
+// MarkOutboxFailed - запоминаем неудачную попытку публикации
+func (repo *Repository) MarkOutboxFailed(ctx context.Context, id int64, reason string) error {
+	_, err := repo.DB.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`, id, reason)
+	if err != nil {
+		return fmt.Errorf("PostgresRepository: error updating outbox event %d: %v", id, err)
+	}
+
+	return nil
+}
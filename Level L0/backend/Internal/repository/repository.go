@@ -3,22 +3,22 @@ package repository
 import (
 	"WbDemoProject/Internal/config"
 	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/repository/codec"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"strconv"
-	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Repository struct {
-	DB      *pgxpool.Pool
-	Config  *config.Config
-	Storage map[string]*entity.Order
-	Mutex   sync.RWMutex
+	DB     *pgxpool.Pool
+	Config *config.Config
 }
 
 func New(cfg *config.Config) (*Repository, error) {
@@ -38,17 +38,32 @@ func New(cfg *config.Config) (*Repository, error) {
 	}
 
 	return &Repository{
-		DB:      dbPool,
-		Config:  cfg,
-		Storage: make(map[string]*entity.Order),
+		DB:     dbPool,
+		Config: cfg,
 	}, nil
 }
 
-// SaveOrderInDB - сохраняем заказ в дб
-func (repo *Repository) SaveOrderInDB(ctx context.Context, order *entity.Order) error {
+// Ping - проверяем соединение с БД
+func (repo *Repository) Ping(ctx context.Context) error {
+	return repo.DB.Ping(ctx)
+}
+
+// Close - закрываем пул соединений с БД
+func (repo *Repository) Close() {
+	repo.DB.Close()
+}
+
+// SaveOrderInDB - идемпотентно сохраняем заказ в дб.
+// Повтор любой уже сохраненной версии заказа только подтверждается, измененный заказ сохраняется новой версией
+func (repo *Repository) SaveOrderInDB(ctx context.Context, order *entity.Order) (entity.SaveStatus, error) {
+	payload, hash, err := codec.OrderPayload(order)
+	if err != nil {
+		return 0, fmt.Errorf("PostgresRepository: cannot marshal order: %v", err)
+	}
+
 	tx, err := repo.DB.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("PostgresRepository: cannot start transaction: %v", err)
+		return 0, fmt.Errorf("PostgresRepository: cannot start transaction: %v", err)
 	}
 
 	// при ошибке откатить назад
@@ -59,9 +74,11 @@ func (repo *Repository) SaveOrderInDB(ctx context.Context, order *entity.Order)
 	}()
 
 	// orders
-	_, err = tx.Exec(ctx, `
-		INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`,
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service,
+		                    shardkey, sm_id, date_created, oof_shard, version, payload_hash, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,1,$12,NOW())
+		ON CONFLICT (order_uid) DO NOTHING`,
 		order.OrderUID,
 		order.TrackNumber,
 		order.Entry,
@@ -73,14 +90,78 @@ func (repo *Repository) SaveOrderInDB(ctx context.Context, order *entity.Order)
 		order.SmID,
 		order.DateCreated,
 		order.OofShard,
+		hash,
 	)
 	if err != nil {
-		return fmt.Errorf("PostgresRepository: error inserting order: %v", err)
+		return 0, fmt.Errorf("PostgresRepository: error inserting order: %v", err)
+	}
+
+	status := entity.OrderCreated
+	version := 1
+
+	// заказ уже есть - сравниваем содержимое
+	if tag.RowsAffected() == 0 {
+		var storedHash *string
+		err = tx.QueryRow(ctx, `SELECT payload_hash, version FROM orders WHERE order_uid = $1 FOR UPDATE`,
+			order.OrderUID).Scan(&storedHash, &version)
+		if err != nil {
+			return 0, fmt.Errorf("PostgresRepository: error locking order: %v", err)
+		}
+
+		// при at-least-once доставке может повторно прийти и старая версия: A, B, снова A.
+		// Такое содержимое уже было, новой версией его не сохраняем, иначе заказ откатился бы
+		known := storedHash != nil && *storedHash == hash
+		if !known {
+			err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM order_revisions WHERE order_uid = $1 AND payload_hash = $2)`,
+				order.OrderUID, hash).Scan(&known)
+			if err != nil {
+				return 0, fmt.Errorf("PostgresRepository: error checking revisions: %v", err)
+			}
+		}
+
+		if known {
+			log.Printf("заказ %s уже сохранен, дубликат пропущен", order.OrderUID)
+			return entity.OrderUnchanged, nil
+		}
+
+		status = entity.OrderUpdated
+		version++
+
+		_, err = tx.Exec(ctx, `
+			UPDATE orders SET track_number = $2, entry = $3, locale = $4, internal_signature = $5, customer_id = $6,
+			                  delivery_service = $7, shardkey = $8, sm_id = $9, date_created = $10, oof_shard = $11,
+			                  version = $12, payload_hash = $13, updated_at = NOW()
+			WHERE order_uid = $1`,
+			order.OrderUID,
+			order.TrackNumber,
+			order.Entry,
+			order.Locale,
+			order.InternalSignature,
+			order.CustomerID,
+			order.DeliveryService,
+			order.ShardKey,
+			order.SmID,
+			order.DateCreated,
+			order.OofShard,
+			version,
+			hash,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("PostgresRepository: error updating order: %v", err)
+		}
+
+		// позиции заказа заменяем целиком
+		_, err = tx.Exec(ctx, `DELETE FROM items WHERE order_uid = $1`, order.OrderUID)
+		if err != nil {
+			return 0, fmt.Errorf("PostgresRepository: error deleting items: %v", err)
+		}
 	}
 
 	// delivery
 	_, err = tx.Exec(ctx, `INSERT INTO delivery (order_uid, name, phone, zip, city, address, region, email)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		ON CONFLICT (order_uid) DO UPDATE SET name = EXCLUDED.name, phone = EXCLUDED.phone, zip = EXCLUDED.zip,
+			city = EXCLUDED.city, address = EXCLUDED.address, region = EXCLUDED.region, email = EXCLUDED.email`,
 		order.OrderUID,
 		order.Delivery.Name,
 		order.Delivery.Phone,
@@ -91,14 +172,18 @@ func (repo *Repository) SaveOrderInDB(ctx context.Context, order *entity.Order)
 		order.Delivery.Email,
 	)
 	if err != nil {
-		return fmt.Errorf("PostgresRepository: error inserting delivery: %v", err)
+		return 0, fmt.Errorf("PostgresRepository: error upserting delivery: %v", err)
 	}
 
 	// payment
 	_, err = tx.Exec(ctx, `
 		INSERT INTO payment (order_uid, transaction, request_id, currency,
 		                     provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`,
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		ON CONFLICT (order_uid) DO UPDATE SET transaction = EXCLUDED.transaction, request_id = EXCLUDED.request_id,
+			currency = EXCLUDED.currency, provider = EXCLUDED.provider, amount = EXCLUDED.amount,
+			payment_dt = EXCLUDED.payment_dt, bank = EXCLUDED.bank, delivery_cost = EXCLUDED.delivery_cost,
+			goods_total = EXCLUDED.goods_total, custom_fee = EXCLUDED.custom_fee`,
 		order.OrderUID,
 		order.Payment.Transaction,
 		order.Payment.RequestID,
@@ -112,7 +197,7 @@ func (repo *Repository) SaveOrderInDB(ctx context.Context, order *entity.Order)
 		order.Payment.CustomFee,
 	)
 	if err != nil {
-		return fmt.Errorf("PostgresRepository: error inserting payment: %v", err)
+		return 0, fmt.Errorf("PostgresRepository: error upserting payment: %v", err)
 	}
 
 	// items
@@ -135,45 +220,94 @@ func (repo *Repository) SaveOrderInDB(ctx context.Context, order *entity.Order)
 			order.OrderUID,
 		)
 		if err != nil {
-			return fmt.Errorf("PostgresRepository: error inserting item: %v", err)
+			return 0, fmt.Errorf("PostgresRepository: error inserting item: %v", err)
 		}
 	}
 
-	//при ошибке транзакции сохраняем данные в кеше чтобы их не потерять
-	if err := tx.Commit(ctx); err != nil {
-		err := repo.SaveOrderInCache(order)
-		if err != nil {
-			return fmt.Errorf("repository: cannot commit transaction: %v", err)
-		}
-		log.Println("заказ не сохранен в бд но сохранен в кэше", err)
+	// история версий
+	_, err = tx.Exec(ctx, `
+		INSERT INTO order_revisions (order_uid, version, payload_hash, payload)
+		VALUES ($1,$2,$3,$4)`,
+		order.OrderUID,
+		version,
+		hash,
+		payload,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("PostgresRepository: error inserting revision: %v", err)
+	}
 
-		return fmt.Errorf("PostgresRepository: commit error: %v", err)
+	// событие для внешних сервисов пишем в той же транзакции
+	eventType := entity.EventOrderCreated
+	if status == entity.OrderUpdated {
+		eventType = entity.EventOrderUpdated
 	}
 
-	log.Printf("заказ сохранен")
+	event, err := json.Marshal(entity.OrderEventPayload{
+		Type:       eventType,
+		OrderUID:   order.OrderUID,
+		Version:    version,
+		OccurredAt: time.Now().UTC(),
+		Order:      payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("PostgresRepository: cannot marshal event: %v", err)
+	}
 
-	return nil
-}
+	_, err = tx.Exec(ctx, `INSERT INTO outbox (event_type, order_uid, payload) VALUES ($1,$2,$3)`,
+		eventType, order.OrderUID, event)
+	if err != nil {
+		return 0, fmt.Errorf("PostgresRepository: error inserting outbox event: %v", err)
+	}
 
-// SaveOrderInCache - сохраняем заказ в кэше
-func (repo *Repository) SaveOrderInCache(order *entity.Order) error {
-	repo.Mutex.Lock()
-	repo.Storage[order.OrderUID] = order
-	defer repo.Mutex.Unlock()
+	//заказ к этому моменту уже лежит в кэше, при ошибке коммита консюмер получит его повторно
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("PostgresRepository: commit error: %v", err)
+	}
+
+	log.Printf("заказ %s сохранен (%s, версия %d)", order.OrderUID, status, version)
 
-	return nil
+	return status, nil
 }
 
-// GetOrderFromCache - кэшируем и получаем заказ из кэша
-func (repo *Repository) GetOrderFromCache(orderUID string) (*entity.Order, bool) {
-	repo.Mutex.RLock()
-	order, ok := repo.Storage[orderUID]
-	defer repo.Mutex.RUnlock()
+// GetOrderRevisions - история версий заказа, от первой к последней
+func (repo *Repository) GetOrderRevisions(ctx context.Context, orderUID string) ([]entity.OrderRevision, error) {
+	rows, err := repo.DB.Query(ctx, `
+		SELECT version, payload_hash, created_at, payload
+		FROM order_revisions
+		WHERE order_uid = $1
+		ORDER BY version`, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error getting revisions: %v", err)
+	}
+	defer rows.Close()
+
+	var revisions []entity.OrderRevision
+
+	for rows.Next() {
+		var revision entity.OrderRevision
+		var payload []byte
+
+		err = rows.Scan(&revision.Version, &revision.PayloadHash, &revision.CreatedAt, &payload)
+		if err != nil {
+			return nil, fmt.Errorf("PostgresRepository: error scanning revision: %v", err)
+		}
+
+		if err = json.Unmarshal(payload, &revision.Order); err != nil {
+			return nil, fmt.Errorf("PostgresRepository: error decoding revision payload: %v", err)
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error reading revisions: %v", err)
+	}
 
-	return order, ok
+	return revisions, nil
 }
 
-// GetOrderFromDB - получаем заказ из бд и кладем в кэш
+// GetOrderFromDB - получаем заказ из бд
 func (repo *Repository) GetOrderFromDB(ctx context.Context, orderUID string) (*entity.Order, error) {
 	var order entity.Order
 
@@ -193,6 +327,9 @@ func (repo *Repository) GetOrderFromDB(ctx context.Context, orderUID string) (*e
 		&order.DateCreated,
 		&order.OofShard,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("PostgresRepository: %w", entity.ErrOrderNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("PostgresRepository: error getting order: %v", err)
 	}
@@ -260,107 +397,83 @@ func (repo *Repository) GetOrderFromDB(ctx context.Context, orderUID string) (*e
 	return &order, nil
 }
 
-// GetAllOrdersFromDB - восстанавливаем кэш
-func (repo *Repository) GetAllOrdersFromDB(ctx context.Context) ([]*entity.Order, error) {
-	rows, err := repo.DB.Query(ctx, `
-       SELECT order_uid, track_number, entry, locale, internal_signature,
-              customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
-       FROM orders`)
-	if err != nil {
-		return nil, fmt.Errorf("PostgresRepository: error getting all orders: %v", err)
-	}
-	defer rows.Close()
-
-	var orders []*entity.Order
+// warmupPageSize - сколько заказов читаем из БД за один запрос при обходе таблицы
+const warmupPageSize = 500
+
+// GetAllOrdersFromDB - обходим заказы от новых к старым страницами и отдаем каждый в fn.
+// Доставка, оплата и товары подтягиваются тем же запросом, в памяти держится только одна страница.
+// limit > 0 ограничивает обход самыми свежими заказами, ошибка из fn останавливает обход
+func (repo *Repository) GetAllOrdersFromDB(ctx context.Context, limit int, fn func(order *entity.Order) error) error {
+	var (
+		lastCreated time.Time
+		lastUID     string
+		total       int
+	)
 
-	for rows.Next() {
-		var order entity.Order
-		err := rows.Scan(
-			&order.OrderUID,
-			&order.TrackNumber,
-			&order.Entry,
-			&order.Locale,
-			&order.InternalSignature,
-			&order.CustomerID,
-			&order.DeliveryService,
-			&order.ShardKey,
-			&order.SmID,
-			&order.DateCreated,
-			&order.OofShard,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("PostgresRepository: error scanning order: %v", err)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		// delivery
-		err = repo.DB.QueryRow(ctx, `
-			SELECT name, phone, zip, city, address, region, email
-			FROM delivery WHERE order_uid = $1`, order.OrderUID).Scan(
-			&order.Delivery.Name,
-			&order.Delivery.Phone,
-			&order.Delivery.Zip,
-			&order.Delivery.City,
-			&order.Delivery.Address,
-			&order.Delivery.Region,
-			&order.Delivery.Email,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("PostgresRepository: error getting delivery: %v", err)
+		pageSize := warmupPageSize
+		if limit > 0 && limit-total < pageSize {
+			pageSize = limit - total
+		}
+		if pageSize <= 0 {
+			return nil
 		}
 
-		// payment
-		err = repo.DB.QueryRow(ctx, `
-			SELECT transaction, request_id, currency, provider, amount, payment_dt,
-				   bank, delivery_cost, goods_total, custom_fee
-			FROM payment WHERE order_uid = $1`, order.OrderUID).Scan(
-			&order.Payment.Transaction,
-			&order.Payment.RequestID,
-			&order.Payment.Currency,
-			&order.Payment.Provider,
-			&order.Payment.Amount,
-			&order.Payment.PaymentDT,
-			&order.Payment.Bank,
-			&order.Payment.DeliveryCost,
-			&order.Payment.GoodsTotal,
-			&order.Payment.CustomFee,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("PostgresRepository: error getting payment: %v", err)
+		query := orderSelect
+		args := []any{pageSize}
+		if lastUID != "" {
+			query += "\n\tWHERE (o.date_created, o.order_uid) < ($2, $3)"
+			args = append(args, lastCreated, lastUID)
 		}
+		query += "\n\tORDER BY o.date_created DESC, o.order_uid DESC\n\tLIMIT $1"
 
-		// items
-		itemRows, err := repo.DB.Query(ctx, `
-			SELECT chrt_id, track_number, price, rid, name, sale,
-			       size, total_price, nm_id, brand, status
-			FROM items WHERE order_uid = $1`, order.OrderUID)
+		page, err := repo.queryOrders(ctx, query, args...)
 		if err != nil {
-			return nil, fmt.Errorf("PostgresRepository: error getting items: %v", err)
+			return err
 		}
 
-		for itemRows.Next() {
-			var item entity.Item
-			err := itemRows.Scan(
-				&item.ChrtID,
-				&item.TrackNumber,
-				&item.Price,
-				&item.Rid,
-				&item.Name,
-				&item.Sale,
-				&item.Size,
-				&item.TotalPrice,
-				&item.NmID,
-				&item.Brand,
-				&item.Status,
-			)
-			if err != nil {
-				itemRows.Close()
-				return nil, fmt.Errorf("PostgresRepository: error scanning item: %v", err)
+		for _, order := range page {
+			if err := fn(order); err != nil {
+				return err
 			}
-			order.Items = append(order.Items, item)
 		}
-		itemRows.Close()
 
-		orders = append(orders, &order)
+		total += len(page)
+
+		if len(page) < pageSize {
+			return nil
+		}
+
+		last := page[len(page)-1]
+		lastCreated, lastUID = last.DateCreated, last.OrderUID
+	}
+}
+
+// queryOrders - выполняем запрос на основе orderSelect и собираем заказы
+func (repo *Repository) queryOrders(ctx context.Context, query string, args ...any) ([]*entity.Order, error) {
+	rows, err := repo.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error getting orders: %v", err)
+	}
+	defer rows.Close()
+
+	var orders []*entity.Order
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error reading orders: %v", err)
 	}
 
 	return orders, nil
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"WbDemoProject/Internal/entity"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// statsGroups - группировка и сумма GMV для каждого разреза статистики
+var statsGroups = map[entity.StatsDimension]struct {
+	key  string
+	from string
+	gmv  string
+}{
+	entity.StatsByDay:             {key: "to_char(o.date_created, 'YYYY-MM-DD')", from: "JOIN payment p ON p.order_uid = o.order_uid", gmv: "p.amount"},
+	entity.StatsByDeliveryService: {key: "o.delivery_service", from: "JOIN payment p ON p.order_uid = o.order_uid", gmv: "p.amount"},
+	entity.StatsByProvider:        {key: "p.provider", from: "JOIN payment p ON p.order_uid = o.order_uid", gmv: "p.amount"},
+	entity.StatsByCurrency:        {key: "p.currency", from: "JOIN payment p ON p.order_uid = o.order_uid", gmv: "p.amount"},
+	entity.StatsByBrand:           {key: "i.brand", from: "JOIN items i ON i.order_uid = o.order_uid", gmv: "i.total_price"},
+}
+
+// OrderStats - сводная статистика и все разрезы за период, считается в БД
+func (repo *Repository) OrderStats(ctx context.Context, filter entity.StatsFilter) (*entity.OrderStats, error) {
+	where, args := statsWhere(filter)
+
+	var stats entity.OrderStats
+
+	err := repo.DB.QueryRow(ctx, `
+	SELECT COUNT(*), COALESCE(SUM(p.amount), 0), COALESCE(AVG(p.amount), 0), COALESCE(AVG(ic.items), 0)
+	FROM orders o
+	JOIN payment p ON p.order_uid = o.order_uid
+	CROSS JOIN LATERAL (SELECT COUNT(*) AS items FROM items i WHERE i.order_uid = o.order_uid) ic`+where, args...).
+		Scan(&stats.Orders, &stats.GMV, &stats.AvgBasket, &stats.AvgItems)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error counting order stats: %v", err)
+	}
+
+	for _, dimension := range entity.StatsDimensions {
+		buckets, err := repo.statsBreakdown(ctx, dimension, where, args)
+		if err != nil {
+			return nil, err
+		}
+
+		stats.SetBreakdown(dimension, buckets)
+	}
+
+	return &stats, nil
+}
+
+func (repo *Repository) statsBreakdown(ctx context.Context, dimension entity.StatsDimension, where string, args []any) ([]entity.StatsBucket, error) {
+	group := statsGroups[dimension]
+
+	// дни по порядку, остальные разрезы - от большего GMV к меньшему
+	order := "3 DESC, 1"
+	if dimension == entity.StatsByDay {
+		order = "1"
+	}
+
+	rows, err := repo.DB.Query(ctx, fmt.Sprintf(`
+	SELECT COALESCE(%[1]s, ''), COUNT(DISTINCT o.order_uid), COALESCE(SUM(%[2]s), 0)
+	FROM orders o
+	%[3]s%[4]s
+	GROUP BY 1
+	ORDER BY %[5]s`, group.key, group.gmv, group.from, where, order), args...)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error getting stats by %s: %v", dimension, err)
+	}
+	defer rows.Close()
+
+	buckets := []entity.StatsBucket{}
+
+	for rows.Next() {
+		var bucket entity.StatsBucket
+		if err = rows.Scan(&bucket.Key, &bucket.Orders, &bucket.GMV); err != nil {
+			return nil, fmt.Errorf("PostgresRepository: error scanning stats by %s: %v", dimension, err)
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("PostgresRepository: error reading stats by %s: %v", dimension, err)
+	}
+
+	return buckets, nil
+}
+
+// statsWhere - условие по периоду для всех запросов статистики
+func statsWhere(filter entity.StatsFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("o.date_created >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("o.date_created < $%d", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	return "\n\tWHERE " + strings.Join(conditions, " AND "), args
+}
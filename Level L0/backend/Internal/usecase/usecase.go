@@ -1,44 +1,74 @@
 package usecase
 
 import (
+	"WbDemoProject/Internal/cache"
 	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/metrics"
+	"WbDemoProject/Internal/validation"
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 )
 
-type RepositoryProvider interface {
-	SaveOrderInDB(ctx context.Context, order *entity.Order) error
-	GetOrderFromCache(orderUID string) (*entity.Order, bool)
+// OrderStore - постоянное хранилище заказов (Postgres или память)
+type OrderStore interface {
+	SaveOrderInDB(ctx context.Context, order *entity.Order) (entity.SaveStatus, error)
 	GetOrderFromDB(ctx context.Context, orderUID string) (*entity.Order, error)
-	SaveOrderInCache(order *entity.Order) error
-	GetAllOrdersFromDB(ctx context.Context) ([]*entity.Order, error)
+	GetAllOrdersFromDB(ctx context.Context, limit int, fn func(order *entity.Order) error) error
+	GetOrderRevisions(ctx context.Context, orderUID string) ([]entity.OrderRevision, error)
+	ListOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderPage, error)
+	ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error
+	OrderStats(ctx context.Context, filter entity.StatsFilter) (*entity.OrderStats, error)
 }
 
+// OrderCache - кэш заказов по order_uid
+type OrderCache interface {
+	Get(orderUID string) (*entity.Order, bool)
+	Set(orderUID string, order *entity.Order)
+	// Warm - добавить заказ как самый давно использованный, если он помещается без вытеснения
+	Warm(orderUID string, order *entity.Order) bool
+	Stats() cache.Stats
+}
+
+// checkedOrdersLimit - сколько проверенных заказов помним, чтобы набор не рос бесконечно
+const checkedOrdersLimit = 100000
+
+// statsCacheTTL - сколько отдаем посчитанную статистику без повторного запроса в БД
+const statsCacheTTL = 30 * time.Second
+
 type Usecase struct {
-	repo          RepositoryProvider
-	checkedOrders map[string]bool
+	store OrderStore
+	cache OrderCache
+	// checkedOrders - заказы из кэша, наличие которых в БД уже проверено
+	checkedOrders cache.Cache[struct{}]
+	// stats - статистика по периодам, обновление дашборда не ходит каждый раз в БД
+	stats cache.Cache[*entity.OrderStats]
 }
 
-func New(repo RepositoryProvider) *Usecase {
+func New(store OrderStore, orderCache OrderCache) *Usecase {
 	return &Usecase{
-		repo:          repo,
-		checkedOrders: make(map[string]bool),
+		store:         store,
+		cache:         orderCache,
+		checkedOrders: cache.NewLRU(cache.Options[struct{}]{MaxEntries: checkedOrdersLimit}),
+		stats:         cache.NewLRU(cache.Options[*entity.OrderStats]{MaxEntries: 100, TTL: statsCacheTTL}),
 	}
 }
 
-// SaveOrderInDB - сохраняем данные заказа в БД
-func (u *Usecase) SaveOrderInDB(ctx context.Context, order *entity.Order) error {
-	if err := u.repo.SaveOrderInDB(ctx, order); err != nil {
-		return fmt.Errorf("failed to save order in DB: %w", err)
+// SaveOrderInDB - сохраняем данные заказа в БД, повторная доставка того же заказа не считается ошибкой
+func (u *Usecase) SaveOrderInDB(ctx context.Context, order *entity.Order) (entity.SaveStatus, error) {
+	status, err := u.store.SaveOrderInDB(ctx, order)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save order in DB: %w", err)
 	}
 
-	return nil
+	return status, nil
 }
 
 // GetOrderFromCache - получаем данные из кэша
 func (u *Usecase) GetOrderFromCache(orderUID string) (*entity.Order, bool) {
-	data, exist := u.repo.GetOrderFromCache(orderUID)
+	data, exist := u.cache.Get(orderUID)
 	if !exist {
 		return nil, false
 	}
@@ -54,7 +84,7 @@ func (u *Usecase) CheckOrderFromCacheInDB(ctx context.Context, orderUID string,
 	}
 
 	// Получаем данные из кэша
-	data, exist := u.repo.GetOrderFromCache(orderUID)
+	data, exist := u.cache.Get(orderUID)
 	if !exist {
 		return nil
 	}
@@ -64,7 +94,7 @@ func (u *Usecase) CheckOrderFromCacheInDB(ctx context.Context, orderUID string,
 	if err != nil {
 		// Если в БД нет записи - добавляем (защита от утечек!)
 		go func(order *entity.Order) {
-			if err := u.repo.SaveOrderInDB(context.Background(), order); err != nil {
+			if _, err := u.store.SaveOrderInDB(context.Background(), order); err != nil {
 				log.Printf("Failed to save order from cache to DB: %v", err)
 			}
 		}(data)
@@ -75,16 +105,18 @@ func (u *Usecase) CheckOrderFromCacheInDB(ctx context.Context, orderUID string,
 
 // SaveOrderInCache - сохраняем заказ в кэше
 func (u *Usecase) SaveOrderInCache(order *entity.Order) error {
-	if err := u.repo.SaveOrderInCache(order); err != nil {
-		return fmt.Errorf("failed to save order in cache: %w", err)
+	if order == nil || order.OrderUID == "" {
+		return fmt.Errorf("failed to save order in cache: empty order_uid")
 	}
 
+	u.cache.Set(order.OrderUID, order)
+
 	return nil
 }
 
 // GetOrderFromDB - получаем заказ из БД и автоматически кэшируем
 func (u *Usecase) GetOrderFromDB(ctx context.Context, orderUID string) (*entity.Order, error) {
-	data, err := u.repo.GetOrderFromDB(ctx, orderUID)
+	data, err := u.store.GetOrderFromDB(ctx, orderUID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch order from DB: %w", err)
 	}
@@ -102,11 +134,13 @@ func (u *Usecase) GetOrder(ctx context.Context, orderUID string) (*entity.Order,
 	//Ищем в кэше ID заказа
 	data, exist := u.GetOrderFromCache(orderUID)
 	//проверяем что заказ уже был проверен чтобы не проверять постоянно бд
-	if exist && !u.checkedOrders[orderUID] {
-		if err := u.CheckOrderFromCacheInDB(ctx, orderUID, exist); err != nil {
-			log.Printf("Warning: failed to check order in DB: %v", err)
+	if exist {
+		if _, checked := u.checkedOrders.Get(orderUID); !checked {
+			if err := u.CheckOrderFromCacheInDB(ctx, orderUID, exist); err != nil {
+				log.Printf("Warning: failed to check order in DB: %v", err)
+			}
+			u.checkedOrders.Set(orderUID, struct{}{})
 		}
-		u.checkedOrders[orderUID] = true
 
 		return data, nil
 	}
@@ -125,21 +159,38 @@ func (u *Usecase) GetOrder(ctx context.Context, orderUID string) (*entity.Order,
 	return data, nil
 }
 
-func (u *Usecase) GetAllOrdersFromDB(ctx context.Context) ([]*entity.Order, error) {
-	data, err := u.repo.GetAllOrdersFromDB(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch all orders from DB: %w", err)
+// errCacheFull - в кэше не осталось места, дальше прогревать нет смысла
+var errCacheFull = errors.New("cache is full")
+
+// WarmUpCache - прогреваем кэш не более чем limit самыми свежими заказами, пока в нем есть место.
+// Заказы приходят от свежих к старым и встают в конец очереди вытеснения, поэтому свежие
+// вытесняются последними, а на заполненном кэше прогрев останавливается, ничего не вытеснив
+func (u *Usecase) WarmUpCache(ctx context.Context, limit int) (int, error) {
+	loaded := 0
+
+	err := u.store.GetAllOrdersFromDB(ctx, limit, func(order *entity.Order) error {
+		if order == nil || order.OrderUID == "" {
+			return fmt.Errorf("failed to save order in cache: empty order_uid")
+		}
+		if !u.cache.Warm(order.OrderUID, order) {
+			return errCacheFull
+		}
+		loaded++
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errCacheFull) {
+		return loaded, fmt.Errorf("failed to warm up cache: %w", err)
 	}
 
-	return data, nil
+	return loaded, nil
 }
 
 // HandleOrder - обработка заказа из Kafka с защитой от потери данных
 func (u *Usecase) HandleOrder(ctx context.Context, order *entity.Order) error {
-	// Проверяем что ID не пустой
-	if order.OrderUID == "" {
-		log.Printf("Invalid order: missing order_uid")
-		return nil
+	// Проверяем заказ, невалидный отдаем консюмеру для отправки в DLQ
+	if err := validation.ValidateOrder(order); err != nil {
+		return fmt.Errorf("order %q rejected: %w", order.OrderUID, err)
 	}
 
 	//сохраняем в кэш
@@ -148,5 +199,88 @@ func (u *Usecase) HandleOrder(ctx context.Context, order *entity.Order) error {
 	}
 
 	// сохраняем в БД
-	return u.SaveOrderInDB(ctx, order)
+	status, err := u.SaveOrderInDB(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	metrics.OrderSaved(status.String())
+
+	return nil
+}
+
+// CacheStats - счетчики кэша заказов
+func (u *Usecase) CacheStats() cache.Stats {
+	return u.cache.Stats()
+}
+
+// GetOrderHistory - история версий заказа
+func (u *Usecase) GetOrderHistory(ctx context.Context, orderUID string) ([]entity.OrderRevision, error) {
+	revisions, err := u.store.GetOrderRevisions(ctx, orderUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order history: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// ListOrders - список заказов с фильтрами и курсорной пагинацией
+func (u *Usecase) ListOrders(ctx context.Context, filter entity.OrderFilter) (*entity.OrderPage, error) {
+	page, err := u.store.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	return page, nil
+}
+
+// ExportOrders - потоково выгружаем заказы за период
+func (u *Usecase) ExportOrders(ctx context.Context, from, to time.Time, fn func(order *entity.Order) error) error {
+	if !from.Before(to) {
+		return fmt.Errorf("invalid export range: from must be before to")
+	}
+
+	if err := u.store.ExportOrders(ctx, from, to, fn); err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	return nil
+}
+
+// OrderStats - статистика заказов за период, на statsCacheTTL берется из кэша
+func (u *Usecase) OrderStats(ctx context.Context, filter entity.StatsFilter) (*entity.OrderStats, error) {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("invalid stats range: from must be before to")
+	}
+
+	key := statsKey(filter)
+	if stats, ok := u.stats.Get(key); ok {
+		return stats, nil
+	}
+
+	stats, err := u.store.OrderStats(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order stats: %w", err)
+	}
+
+	u.stats.Set(key, stats)
+
+	return stats, nil
+}
+
+// StatsCacheStats - счетчики кэша статистики
+func (u *Usecase) StatsCacheStats() cache.Stats {
+	return u.stats.Stats()
+}
+
+func statsKey(filter entity.StatsFilter) string {
+	var from, to string
+	if filter.From != nil {
+		from = filter.From.UTC().Format(time.RFC3339Nano)
+	}
+	if filter.To != nil {
+		to = filter.To.UTC().Format(time.RFC3339Nano)
+	}
+
+	return from + "|" + to
 }
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"WbDemoProject/Internal/cache"
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/repository/memory"
+	"WbDemoProject/Internal/testutil"
+	"WbDemoProject/Internal/validation"
+	"context"
+	"errors"
+	"testing"
+)
+
+func newTestUsecase() (*Usecase, *memory.Store, *cache.LRU[*entity.Order]) {
+	store := memory.New()
+	orderCache := cache.NewLRU(cache.Options[*entity.Order]{MaxEntries: 10})
+
+	return New(store, orderCache), store, orderCache
+}
+
+func TestGetOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		inCache   bool
+		inStore   bool
+		wantErr   error
+		wantCache bool
+	}{
+		{name: "cache hit", inCache: true, inStore: true, wantCache: true},
+		{name: "db fallback fills cache", inStore: true, wantCache: true},
+		{name: "not found", wantErr: entity.ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, store, orderCache := newTestUsecase()
+			order := testutil.ValidOrder("order-1")
+
+			if tt.inStore {
+				if _, err := store.SaveOrderInDB(context.Background(), order); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.inCache {
+				orderCache.Set(order.OrderUID, order)
+			}
+
+			got, err := u.GetOrder(context.Background(), order.OrderUID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetOrder() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetOrder() error = %v", err)
+			}
+
+			if got.OrderUID != order.OrderUID {
+				t.Errorf("GetOrder() uid = %q, want %q", got.OrderUID, order.OrderUID)
+			}
+
+			if _, ok := orderCache.Get(order.OrderUID); ok != tt.wantCache {
+				t.Errorf("order in cache = %v, want %v", ok, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestHandleOrder(t *testing.T) {
+	invalid := testutil.ValidOrder("order-invalid")
+	invalid.Payment.Currency = "XXX"
+
+	tests := []struct {
+		name        string
+		orders      []*entity.Order
+		wantInvalid bool
+		wantVersion int
+	}{
+		{name: "valid order", orders: []*entity.Order{testutil.ValidOrder("order-1")}, wantVersion: 1},
+		{name: "invalid order", orders: []*entity.Order{invalid}, wantInvalid: true},
+		{
+			name:        "duplicate delivery",
+			orders:      []*entity.Order{testutil.ValidOrder("order-1"), testutil.ValidOrder("order-1")},
+			wantVersion: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, store, orderCache := newTestUsecase()
+
+			var err error
+			for _, order := range tt.orders {
+				if err = u.HandleOrder(context.Background(), order); err != nil {
+					break
+				}
+			}
+
+			uid := tt.orders[0].OrderUID
+
+			if tt.wantInvalid {
+				var validationErrs validation.Errors
+				if !errors.As(err, &validationErrs) {
+					t.Fatalf("HandleOrder() error = %v, want validation.Errors", err)
+				}
+				if orderCache.Len() != 0 {
+					t.Errorf("invalid order must not be cached")
+				}
+				if _, err := store.GetOrderFromDB(context.Background(), uid); !errors.Is(err, entity.ErrOrderNotFound) {
+					t.Errorf("invalid order must not be stored, got err = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleOrder() error = %v", err)
+			}
+
+			revisions, err := store.GetOrderRevisions(context.Background(), uid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(revisions) != tt.wantVersion {
+				t.Errorf("revisions = %d, want %d", len(revisions), tt.wantVersion)
+			}
+
+			if _, ok := orderCache.Get(uid); !ok {
+				t.Errorf("order %q is not cached", uid)
+			}
+		})
+	}
+}
+
+func TestWarmUpCacheStopsWhenFull(t *testing.T) {
+	store := memory.New()
+	orderCache := cache.NewLRU(cache.Options[*entity.Order]{MaxEntries: 2})
+	u := New(store, orderCache)
+
+	for _, uid := range []string{"a", "b", "c", "d"} {
+		if _, err := store.SaveOrderInDB(context.Background(), testutil.ValidOrder(uid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := u.WarmUpCache(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("WarmUpCache() error = %v", err)
+	}
+
+	if loaded != 2 {
+		t.Errorf("loaded = %d, want 2 (stop before eviction)", loaded)
+	}
+	if orderCache.Len() != 2 {
+		t.Errorf("cache len = %d, want 2", orderCache.Len())
+	}
+
+	// даты у заказов одинаковые, свежее тот, у кого order_uid больше
+	for _, uid := range []string{"d", "c"} {
+		if _, ok := orderCache.Get(uid); !ok {
+			t.Errorf("fresh order %q is not cached", uid)
+		}
+	}
+	for _, uid := range []string{"b", "a"} {
+		if _, ok := orderCache.Get(uid); ok {
+			t.Errorf("old order %q is cached instead of a fresher one", uid)
+		}
+	}
+}
+
+// countingStore - считает запросы статистики в хранилище
+type countingStore struct {
+	*memory.Store
+	statsCalls int
+}
+
+func (s *countingStore) OrderStats(ctx context.Context, filter entity.StatsFilter) (*entity.OrderStats, error) {
+	s.statsCalls++
+	return s.Store.OrderStats(ctx, filter)
+}
+
+func TestOrderStatsCached(t *testing.T) {
+	store := &countingStore{Store: memory.New()}
+	u := New(store, cache.NewLRU(cache.Options[*entity.Order]{MaxEntries: 10}))
+
+	from := testutil.ValidOrder("x").DateCreated
+	filters := []entity.StatsFilter{{}, {}, {From: &from}, {From: &from}}
+
+	for _, filter := range filters {
+		if _, err := u.OrderStats(context.Background(), filter); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if store.statsCalls != 2 {
+		t.Errorf("store stats calls = %d, want 2 (one per distinct period)", store.statsCalls)
+	}
+}
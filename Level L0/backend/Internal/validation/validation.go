@@ -0,0 +1,142 @@
+package validation
+
+import (
+	"WbDemoProject/Internal/entity"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+	phoneRegexp = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+)
+
+// currencies - поддерживаемые коды валют ISO 4217
+var currencies = map[string]struct{}{
+	"AED": {}, "AMD": {}, "AUD": {}, "AZN": {}, "BYN": {}, "CAD": {}, "CHF": {}, "CNY": {},
+	"CZK": {}, "DKK": {}, "EUR": {}, "GBP": {}, "GEL": {}, "HKD": {}, "ILS": {}, "INR": {},
+	"JPY": {}, "KGS": {}, "KRW": {}, "KZT": {}, "MDL": {}, "NOK": {}, "PLN": {}, "RUB": {},
+	"SEK": {}, "SGD": {}, "TJS": {}, "TRY": {}, "UAH": {}, "USD": {}, "UZS": {},
+}
+
+// FieldError - ошибка валидации конкретного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Errors - все ошибки валидации заказа
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Error())
+	}
+
+	return "invalid order: " + strings.Join(messages, "; ")
+}
+
+// ValidateOrder - проверяем заказ, возвращаем Errors со всеми найденными ошибками
+func ValidateOrder(order *entity.Order) error {
+	var errs Errors
+
+	add := func(field, message string) {
+		errs = append(errs, FieldError{Field: field, Message: message})
+	}
+
+	if order == nil {
+		add("order", "is empty")
+		return errs
+	}
+
+	// обязательные поля заказа
+	required := []struct {
+		field string
+		value string
+	}{
+		{"order_uid", order.OrderUID},
+		{"track_number", order.TrackNumber},
+		{"entry", order.Entry},
+		{"customer_id", order.CustomerID},
+		{"delivery_service", order.DeliveryService},
+		{"delivery.name", order.Delivery.Name},
+		{"delivery.phone", order.Delivery.Phone},
+		{"delivery.city", order.Delivery.City},
+		{"delivery.address", order.Delivery.Address},
+		{"delivery.email", order.Delivery.Email},
+		{"payment.transaction", order.Payment.Transaction},
+		{"payment.currency", order.Payment.Currency},
+		{"payment.provider", order.Payment.Provider},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			add(r.field, "is required")
+		}
+	}
+
+	if order.DateCreated.IsZero() {
+		add("date_created", "is required")
+	}
+
+	// формат контактов
+	if order.Delivery.Email != "" && !emailRegexp.MatchString(order.Delivery.Email) {
+		add("delivery.email", "invalid format")
+	}
+
+	if order.Delivery.Phone != "" && !phoneRegexp.MatchString(order.Delivery.Phone) {
+		add("delivery.phone", "invalid format")
+	}
+
+	// валюта
+	if order.Payment.Currency != "" {
+		if _, ok := currencies[order.Payment.Currency]; !ok {
+			add("payment.currency", fmt.Sprintf("unsupported currency %q", order.Payment.Currency))
+		}
+	}
+
+	if order.Payment.Amount < 0 || order.Payment.GoodsTotal < 0 ||
+		order.Payment.DeliveryCost < 0 || order.Payment.CustomFee < 0 {
+		add("payment", "amounts must not be negative")
+	}
+
+	// товары
+	if len(order.Items) == 0 {
+		add("items", "at least one item is required")
+	}
+
+	goodsTotal := 0
+	for i, item := range order.Items {
+		field := fmt.Sprintf("items[%d]", i)
+
+		if item.TrackNumber != order.TrackNumber {
+			add(field+".track_number", fmt.Sprintf("%q does not match order track_number %q", item.TrackNumber, order.TrackNumber))
+		}
+
+		if item.Name == "" {
+			add(field+".name", "is required")
+		}
+
+		if item.Price < 0 || item.TotalPrice < 0 {
+			add(field, "prices must not be negative")
+		}
+
+		goodsTotal += item.TotalPrice
+	}
+
+	// сумма товаров должна совпадать с goods_total
+	if len(order.Items) > 0 && goodsTotal != order.Payment.GoodsTotal {
+		add("payment.goods_total", fmt.Sprintf("%d does not match sum of items total_price %d", order.Payment.GoodsTotal, goodsTotal))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
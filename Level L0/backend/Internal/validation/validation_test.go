@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"WbDemoProject/Internal/entity"
+	"WbDemoProject/Internal/testutil"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestValidateOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		mutate     func(order *entity.Order)
+		wantFields []string
+	}{
+		{name: "valid", mutate: func(*entity.Order) {}},
+		{
+			name:       "missing uid",
+			mutate:     func(o *entity.Order) { o.OrderUID = "" },
+			wantFields: []string{"order_uid"},
+		},
+		{
+			name:       "bad email and phone",
+			mutate:     func(o *entity.Order) { o.Delivery.Email = "nope"; o.Delivery.Phone = "123" },
+			wantFields: []string{"delivery.email", "delivery.phone"},
+		},
+		{
+			name:       "unsupported currency",
+			mutate:     func(o *entity.Order) { o.Payment.Currency = "XXX" },
+			wantFields: []string{"payment.currency"},
+		},
+		{
+			name:       "goods total mismatch",
+			mutate:     func(o *entity.Order) { o.Payment.GoodsTotal = 1 },
+			wantFields: []string{"payment.goods_total"},
+		},
+		{
+			name:       "item track number mismatch",
+			mutate:     func(o *entity.Order) { o.Items[0].TrackNumber = "OTHER" },
+			wantFields: []string{"items[0].track_number"},
+		},
+		{
+			name:       "no items",
+			mutate:     func(o *entity.Order) { o.Items = nil },
+			wantFields: []string{"items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := testutil.ValidOrder("order-1")
+			tt.mutate(order)
+
+			err := ValidateOrder(order)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateOrder() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("ValidateOrder() error = %v, want Errors", err)
+			}
+
+			var fields []string
+			for _, fieldErr := range errs {
+				fields = append(fields, fieldErr.Field)
+			}
+
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
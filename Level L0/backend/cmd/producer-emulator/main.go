@@ -0,0 +1,86 @@
+package main
+
+import (
+	"WbDemoProject/Internal/loadgen"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Генератор нагрузки и тестовых данных: случайные согласованные заказы с нужной скоростью,
+// с долей невалидных сообщений и повторных отправок, в Kafka, файл или stdout (NDJSON).
+//
+//	go run ./cmd/producer-emulator -rate 200 -duration 1m -invalid 0.05 -duplicates 0.1
+//	go run ./cmd/producer-emulator -output stdout -count 10 -min-items 2 -max-items 5
+func main() {
+	var (
+		output      = flag.String("output", "kafka", "куда отправлять: kafka, stdout или file")
+		brokers     = flag.String("brokers", "localhost:29092", "адреса брокеров Kafka через запятую")
+		topic       = flag.String("topic", "orders", "топик Kafka")
+		file        = flag.String("file", "orders.ndjson", "файл для -output file")
+		rate        = flag.Float64("rate", 10, "сообщений в секунду, 0 - без ограничения")
+		count       = flag.Int("count", 1, "сколько сообщений отправить, 0 - без ограничения")
+		duration    = flag.Duration("duration", 0, "длительность нагрузки, 0 - без ограничения")
+		minItems    = flag.Int("min-items", 1, "минимум товаров в заказе")
+		maxItems    = flag.Int("max-items", 3, "максимум товаров в заказе")
+		invalid     = flag.Float64("invalid", 0, "доля невалидных сообщений (0..1)")
+		duplicates  = flag.Float64("duplicates", 0, "доля повторных отправок (0..1)")
+		seed        = flag.Uint64("seed", uint64(time.Now().UnixNano()), "seed генератора для воспроизводимых данных")
+		reportEvery = flag.Duration("report", 5*time.Second, "как часто печатать пропускную способность")
+	)
+	flag.Parse()
+
+	if *invalid < 0 || *invalid > 1 || *duplicates < 0 || *duplicates > 1 {
+		log.Fatal("-invalid и -duplicates должны быть в диапазоне 0..1")
+	}
+
+	var sink loadgen.Sink
+	switch *output {
+	case "kafka":
+		sink = loadgen.NewKafkaSink(strings.Split(*brokers, ","), *topic)
+	case "stdout":
+		sink = loadgen.NewNDJSONSink(os.Stdout)
+	case "file":
+		var err error
+		if sink, err = loadgen.NewNDJSONFileSink(*file); err != nil {
+			log.Fatal("ошибка создания файла ", err)
+		}
+	default:
+		log.Fatalf("неизвестный -output %q", *output)
+	}
+
+	gen := loadgen.NewGenerator(loadgen.Options{
+		MinItems:       *minItems,
+		MaxItems:       *maxItems,
+		InvalidRatio:   *invalid,
+		DuplicateRatio: *duplicates,
+		Seed:           *seed,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// отчет пишем в stderr, чтобы не смешивать его с NDJSON в stdout
+	stats, err := loadgen.Run(ctx, gen, sink, loadgen.RunOptions{
+		Rate:        *rate,
+		Count:       *count,
+		Duration:    *duration,
+		ReportEvery: *reportEvery,
+		Report:      os.Stderr,
+	})
+
+	if closeErr := sink.Close(); closeErr != nil {
+		log.Printf("ошибка закрытия: %v", closeErr)
+	}
+
+	if err != nil {
+		log.Fatal("ошибка отправки заказов ", err)
+	}
+
+	log.Println("готово:", stats)
+}
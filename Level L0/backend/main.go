@@ -1,7 +1,20 @@
 package main
 
-import "WbDemoProject/Internal/app"
+import (
+	"WbDemoProject/Internal/app"
+	"context"
+	"log"
+	"os"
+)
 
 func main() {
+	// ./main migrate [up|down|status|redo]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(context.Background(), os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	app.Run()
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"L2_10/internal/cli"
+	"L2_10/internal/sorting"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// коды выхода как у GNU sort: 1 - данные не отсортированы (-c), 2 - ошибка
+const (
+	exitDisorder = 1
+	exitError    = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := cli.ParseOptions(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "sort:", err)
+		return exitError
+	}
+
+	cmp := sorting.NewComparator(opts.Keys, sorting.CompareOptions{
+		Global:    opts.Global,
+		Separator: opts.Separator,
+		Unique:    opts.Unique,
+		Stable:    opts.Stable,
+	})
+	sorter := sorting.New(cmp, sorting.Options{
+		MemoryBytes: opts.Memory,
+		ChunkLines:  opts.Chunk,
+		TempDir:     opts.TempDir,
+		Unique:      opts.Unique,
+		Stable:      opts.Stable,
+		Workers:     opts.Parallel,
+	})
+
+	names := opts.Files
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	if opts.Check && len(names) > 1 {
+		fmt.Fprintln(stderr, "sort: -c принимает только один файл")
+		return exitError
+	}
+
+	inputs := make([]io.Reader, 0, len(names))
+	for _, name := range names {
+		if name == "-" {
+			inputs = append(inputs, stdin)
+			continue
+		}
+
+		file, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(stderr, "sort:", err)
+			return exitError
+		}
+		defer file.Close()
+
+		inputs = append(inputs, file)
+	}
+
+	if opts.Check {
+		var disorder *sorting.DisorderError
+
+		err = sorter.Check(inputs[0])
+		if errors.As(err, &disorder) {
+			fmt.Fprintf(stderr, "sort: %s:%s\n", names[0], disorder)
+			return exitDisorder
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "sort:", err)
+			return exitError
+		}
+
+		return 0
+	}
+
+	if err = sorter.Sort(stdout, inputs...); err != nil {
+		fmt.Fprintln(stderr, "sort:", err)
+		return exitError
+	}
+
+	return 0
+}
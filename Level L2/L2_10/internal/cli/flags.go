@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"L2_10/internal/sorting"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Options - разобранные флаги командной строки
+type Options struct {
+	Keys      []sorting.Key // -k, можно указать несколько раз
+	Global    sorting.Key   // -n -r -M -h -b для всех ключей без своих опций
+	Separator string        // -t
+	Unique    bool          // -u
+	Stable    bool          // -s
+	Parallel  int           // -parallel
+	Check     bool          // -c
+	Memory    int64         // -S
+	Chunk     int           // -chunk
+	TempDir   string        // -T
+	Files     []string      // файлы, пусто или "-" - stdin
+}
+
+// keysFlag - повторяемый флаг -k
+type keysFlag []sorting.Key
+
+func (k *keysFlag) String() string { return fmt.Sprint(len(*k)) }
+
+func (k *keysFlag) Set(value string) error {
+	key, err := sorting.ParseKey(value)
+	if err != nil {
+		return err
+	}
+
+	*k = append(*k, key)
+
+	return nil
+}
+
+// boolFlags - однобуквенные флаги, которые можно склеивать: -nru
+const boolFlags = "nrMhbucs"
+
+// valueFlags - флаги, значение которых можно писать слитно: -k2,3n, -t,
+const valueFlags = "ktST"
+
+// ParseOptions - подключаем флаги
+func ParseOptions(args []string) (Options, error) {
+	var opts Options
+	var keys keysFlag
+	var memory string
+
+	fs := flag.NewFlagSet("sort", flag.ContinueOnError)
+	fs.Var(&keys, "k", "ключ сортировки F[.C][OPTS][,F[.C][OPTS]], например -k2,3n")
+	fs.StringVar(&opts.Separator, "t", "", "разделитель полей (по умолчанию переход от пробелов к символам)")
+	fs.BoolVar(&opts.Global.Numeric, "n", false, "сортировать по числовому значению")
+	fs.BoolVar(&opts.Global.Reverse, "r", false, "обратный порядок")
+	fs.BoolVar(&opts.Global.Month, "M", false, "сортировать по названию месяца (JAN < ... < DEC)")
+	fs.BoolVar(&opts.Global.Human, "h", false, "сортировать по размеру с суффиксом (2K < 1M)")
+	fs.BoolVar(&opts.Global.StartBlanks, "b", false, "игнорировать пробелы в начале полей")
+	fs.BoolVar(&opts.Unique, "u", false, "выводить только первую из строк с равными ключами")
+	fs.BoolVar(&opts.Check, "c", false, "только проверить, отсортированы ли данные")
+	fs.BoolVar(&opts.Stable, "s", false, "стабильная сортировка: не сравнивать строки целиком при равных ключах")
+	fs.IntVar(&opts.Parallel, "parallel", 0, "сколько кусков сортировать параллельно (0 - по числу CPU)")
+	fs.StringVar(&memory, "S", "64M", "размер буфера в памяти (K, M, G)")
+	fs.IntVar(&opts.Chunk, "chunk", 0, "максимум строк в куске (0 - ограничение только по памяти)")
+	fs.StringVar(&opts.TempDir, "T", os.TempDir(), "каталог для временных файлов")
+
+	if err := fs.Parse(expandShortFlags(args)); err != nil {
+		return Options{}, err
+	}
+
+	if opts.Global.Numeric && opts.Global.Month || opts.Global.Numeric && opts.Global.Human || opts.Global.Month && opts.Global.Human {
+		return Options{}, fmt.Errorf("флаги -n, -M и -h несовместимы")
+	}
+
+	if len([]rune(opts.Separator)) > 1 {
+		return Options{}, fmt.Errorf("разделитель -t должен быть одним символом: %q", opts.Separator)
+	}
+
+	size, err := ParseSize(memory)
+	if err != nil {
+		return Options{}, err
+	}
+
+	opts.Global.EndBlanks = opts.Global.StartBlanks
+	opts.Keys = keys
+	opts.Memory = size
+	opts.Files = fs.Args()
+
+	return opts, nil
+}
+
+// expandShortFlags - приводим GNU запись к виду, который понимает пакет flag:
+// -nru превращаем в -n -r -u, -k2,3n в -k 2,3n
+func expandShortFlags(args []string) []string {
+	expanded := make([]string, 0, len(args))
+
+	for i, arg := range args {
+		if arg == "--" {
+			return append(expanded, args[i:]...)
+		}
+
+		if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' || strings.Contains(arg, "=") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		name := arg[1:]
+
+		switch {
+		case strings.IndexByte(valueFlags, name[0]) >= 0:
+			expanded = append(expanded, arg[:2], arg[2:])
+		case strings.Trim(name, boolFlags) == "":
+			for _, c := range name {
+				expanded = append(expanded, "-"+string(c))
+			}
+		default:
+			expanded = append(expanded, arg)
+		}
+	}
+
+	return expanded
+}
+
+// ParseSize - 1024, 512K, 64M, 1G
+func ParseSize(s string) (int64, error) {
+	multiplier := int64(1)
+
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier, s = 1<<10, strings.TrimSuffix(s, "K")
+	case strings.HasSuffix(s, "M"):
+		multiplier, s = 1<<20, strings.TrimSuffix(s, "M")
+	case strings.HasSuffix(s, "G"):
+		multiplier, s = 1<<30, strings.TrimSuffix(s, "G")
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("неверный размер буфера %q", s)
+	}
+
+	return n * multiplier, nil
+}
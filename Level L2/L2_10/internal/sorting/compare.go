@@ -0,0 +1,283 @@
+package sorting
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var months = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// humanSuffixes - суффиксы для -h, каждый следующий в 1024 раза больше
+const humanSuffixes = "KMGTPEZY"
+
+// compareFunc - сравнение значений ключа
+type compareFunc func(a, b string) int
+
+// keyComparer - звено цепочки сравнения: ключ и способ сравнения с учетом его флагов
+type keyComparer struct {
+	key     Key
+	compare compareFunc
+}
+
+// CompareOptions - общие настройки сравнения
+type CompareOptions struct {
+	// Global - флаги -n -r -M -h -b, которые наследуют ключи без своих опций
+	Global Key
+	// Separator - разделитель полей, пустой - поля разделяются переходом от пробелов к символам
+	Separator string
+	// Unique - -u, строки с равными ключами считаются одинаковыми
+	Unique bool
+	// Stable - -s, равные по ключам строки остаются в исходном порядке
+	Stable bool
+}
+
+// Comparator - сравнение строк цепочкой ключей с правилами GNU sort:
+// первый ключ, при равенстве второй и так далее, у каждого свои флаги
+type Comparator struct {
+	chain     []keyComparer
+	separator string
+	// lastResort - при равенстве всех ключей сравниваем строки целиком (выключается -u и -s)
+	lastResort        bool
+	lastResortReverse bool
+}
+
+// NewComparator - keys пустой - ключом служит вся строка с глобальными опциями
+func NewComparator(keys []Key, opts CompareOptions) *Comparator {
+	if len(keys) == 0 {
+		whole := opts.Global
+		whole.StartField, whole.StartChar = 1, 1
+		whole.EndField, whole.EndChar = 0, 0
+		keys = []Key{whole}
+	}
+
+	chain := make([]keyComparer, 0, len(keys))
+	for _, key := range keys {
+		key = key.inherit(opts.Global)
+		chain = append(chain, keyComparer{key: key, compare: compareFor(key)})
+	}
+
+	return &Comparator{
+		chain:             chain,
+		separator:         opts.Separator,
+		lastResort:        !opts.Unique && !opts.Stable,
+		lastResortReverse: opts.Global.Reverse,
+	}
+}
+
+// compareFor - способ сравнения ключа, -r разворачивает результат
+func compareFor(key Key) compareFunc {
+	var compare compareFunc
+
+	switch {
+	case key.Numeric:
+		compare = func(a, b string) int { return cmp.Compare(parseNumber(a), parseNumber(b)) }
+	case key.Human:
+		compare = func(a, b string) int { return cmp.Compare(parseHuman(a), parseHuman(b)) }
+	case key.Month:
+		compare = func(a, b string) int { return cmp.Compare(parseMonth(a), parseMonth(b)) }
+	default:
+		compare = strings.Compare
+	}
+
+	if key.Reverse {
+		forward := compare
+		compare = func(a, b string) int { return forward(b, a) }
+	}
+
+	return compare
+}
+
+// Compare - <0 если a идет раньше b
+func (c *Comparator) Compare(a, b string) int {
+	if r := c.compareKeys(a, b); r != 0 {
+		return r
+	}
+
+	if !c.lastResort {
+		return 0
+	}
+
+	if c.lastResortReverse {
+		return strings.Compare(b, a)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// Equal - строки совпадают по всем ключам (для -u)
+func (c *Comparator) Equal(a, b string) bool {
+	return c.compareKeys(a, b) == 0
+}
+
+func (c *Comparator) compareKeys(a, b string) int {
+	for _, link := range c.chain {
+		if r := link.compare(c.extract(a, link.key), c.extract(b, link.key)); r != 0 {
+			return r
+		}
+	}
+
+	return 0
+}
+
+// extract - часть строки, которую покрывает ключ
+func (c *Comparator) extract(line string, key Key) string {
+	start := len(line)
+	if fieldStart, fieldEnd, ok := c.field(line, key.StartField); ok {
+		pos := fieldStart
+		if key.StartBlanks {
+			pos = skipBlanks(line, pos, fieldEnd)
+		}
+		start = advance(line, pos, fieldEnd, key.StartChar-1)
+	}
+
+	end := len(line)
+	if key.EndField > 0 {
+		if fieldStart, fieldEnd, ok := c.field(line, key.EndField); ok {
+			end = fieldEnd
+			if key.EndChar > 0 {
+				pos := fieldStart
+				if key.EndBlanks {
+					pos = skipBlanks(line, pos, fieldEnd)
+				}
+				end = advance(line, pos, fieldEnd, key.EndChar)
+			}
+		}
+	}
+
+	if end < start {
+		return ""
+	}
+
+	return line[start:end]
+}
+
+// field - границы n-го поля [начало, конец), без выделения памяти на сравнение
+func (c *Comparator) field(line string, n int) (int, int, bool) {
+	pos := 0
+
+	if c.separator != "" {
+		for ; n > 1; n-- {
+			i := strings.Index(line[pos:], c.separator)
+			if i < 0 {
+				return 0, 0, false
+			}
+			pos += i + len(c.separator)
+		}
+
+		end := len(line)
+		if i := strings.Index(line[pos:], c.separator); i >= 0 {
+			end = pos + i
+		}
+
+		return pos, end, true
+	}
+
+	// без -t поле - это пробелы перед ним и непробельные символы
+	for {
+		if pos >= len(line) && (n > 1 || pos > 0) {
+			return 0, 0, false
+		}
+
+		start := pos
+		pos = skipBlanks(line, pos, len(line))
+		for pos < len(line) && !isBlank(line[pos]) {
+			pos++
+		}
+
+		if n == 1 {
+			return start, pos, true
+		}
+		n--
+	}
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+func skipBlanks(line string, pos, limit int) int {
+	for pos < limit && isBlank(line[pos]) {
+		pos++
+	}
+
+	return pos
+}
+
+// advance - сдвигаемся на n символов, но не дальше limit
+func advance(line string, pos, limit, n int) int {
+	for ; n > 0 && pos < limit; n-- {
+		_, size := utf8.DecodeRuneInString(line[pos:])
+		pos += size
+	}
+
+	return min(pos, limit)
+}
+
+// numericPrefix - число в начале строки: [-]цифры[.цифры]
+func numericPrefix(s string) (string, string) {
+	s = strings.TrimLeft(s, " \t")
+
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	digits := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+
+	if i == digits || s[digits:i] == "." {
+		return "", s
+	}
+
+	return s[:i], s[i:]
+}
+
+// parseNumber - строки без числа считаются нулем, как в GNU sort
+func parseNumber(s string) float64 {
+	prefix, _ := numericPrefix(s)
+	n, _ := strconv.ParseFloat(prefix, 64)
+
+	return n
+}
+
+// parseHuman - число с суффиксом K, M, G...: 2K < 1M
+func parseHuman(s string) float64 {
+	prefix, rest := numericPrefix(s)
+	n, _ := strconv.ParseFloat(prefix, 64)
+
+	if rest != "" {
+		suffix := rest[0]
+		if suffix == 'k' {
+			suffix = 'K'
+		}
+		if i := strings.IndexByte(humanSuffixes, suffix); i >= 0 {
+			for ; i >= 0; i-- {
+				n *= 1024
+			}
+		}
+	}
+
+	return n
+}
+
+// parseMonth - JAN..DEC в 1..12, остальное 0
+func parseMonth(s string) int {
+	s = strings.TrimLeft(s, " \t")
+	if len(s) < 3 {
+		return 0
+	}
+
+	return months[strings.ToUpper(s[:3])]
+}
@@ -0,0 +1,365 @@
+package sorting
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// lineOverhead - примерный расход памяти на строку сверх ее длины (заголовок строки в срезе)
+const lineOverhead = 16
+
+// Options - ограничения внешней сортировки
+type Options struct {
+	// MemoryBytes - сколько данных держим в памяти до сброса куска на диск
+	MemoryBytes int64
+	// ChunkLines - максимум строк в куске, 0 - без ограничения
+	ChunkLines int
+	// TempDir - каталог для временных файлов, пустой - os.TempDir()
+	TempDir string
+	// Unique - выводить только первую из строк с равными ключами
+	Unique bool
+	// Stable - сохранять исходный порядок строк с равными ключами
+	Stable bool
+	// Workers - сколько кусков сортируется параллельно, 0 - по числу CPU.
+	// Лимит памяти делится между ними
+	Workers int
+}
+
+// Sorter - внешняя сортировка: куски сортируются в памяти, сбрасываются во временные файлы
+// и сливаются k-way слиянием через кучу
+type Sorter struct {
+	cmp  *Comparator
+	opts Options
+}
+
+// New - конструктор
+func New(cmp *Comparator, opts Options) *Sorter {
+	if opts.MemoryBytes <= 0 {
+		opts.MemoryBytes = 64 << 20
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	return &Sorter{cmp: cmp, opts: opts}
+}
+
+// Sort - сортируем строки всех inputs и пишем результат в w.
+// Временные файлы удаляются в любом случае, в том числе при ошибке
+func (s *Sorter) Sort(w io.Writer, inputs ...io.Reader) (err error) {
+	reader := newLineReader(inputs)
+	out := bufio.NewWriter(w)
+
+	// каждый воркер держит в памяти свой кусок, поэтому лимит делим между ними
+	chunkBytes := max(s.opts.MemoryBytes/int64(s.opts.Workers), 1)
+
+	first, readErr := s.readChunk(reader, chunkBytes)
+	if readErr != nil && !errors.Is(readErr, io.EOF) {
+		return readErr
+	}
+
+	// все влезло в один кусок - сортируем в памяти без временных файлов
+	if errors.Is(readErr, io.EOF) {
+		s.sortChunk(first)
+		if err = s.writeLines(out, first); err != nil {
+			return err
+		}
+
+		return out.Flush()
+	}
+
+	chunks, err := s.sortChunks(reader, first, chunkBytes)
+	defer func() {
+		for _, name := range chunks {
+			if name == "" {
+				continue
+			}
+			if removeErr := os.Remove(name); removeErr != nil && err == nil {
+				err = removeErr
+			}
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	if err = s.merge(out, chunks); err != nil {
+		return err
+	}
+
+	return out.Flush()
+}
+
+// chunkJob - кусок для сортировки и его номер во входных данных
+type chunkJob struct {
+	index int
+	lines []string
+}
+
+// sortChunks - читаем куски и отдаем пулу воркеров, которые сортируют их и сбрасывают на диск.
+// Имена временных файлов возвращаются в порядке кусков во входных данных - это нужно для стабильного слияния
+func (s *Sorter) sortChunks(reader *lineReader, first []string, chunkBytes int64) ([]string, error) {
+	jobs := make(chan chunkJob)
+	done := make(chan struct{})
+
+	var (
+		mu       sync.Mutex
+		chunks   []string
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if firstErr == nil {
+			firstErr = err
+			close(done)
+		}
+	}
+
+	for range s.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				s.sortChunk(job.lines)
+				name, err := s.writeChunk(job.lines)
+
+				mu.Lock()
+				if name != "" {
+					chunks[job.index] = name
+				}
+				mu.Unlock()
+
+				if err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	lines := first
+	var readErr error
+
+	for index := 0; len(lines) > 0; index++ {
+		mu.Lock()
+		chunks = append(chunks, "")
+		mu.Unlock()
+
+		select {
+		case jobs <- chunkJob{index: index, lines: lines}:
+		case <-done:
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+
+		lines, readErr = s.readChunk(reader, chunkBytes)
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			fail(readErr)
+			break
+		}
+
+		select {
+		case <-done:
+			lines = nil
+		default:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return chunks, firstErr
+}
+
+// readChunk - читаем строки, пока не упремся в лимит памяти или строк. io.EOF - входные данные кончились
+func (s *Sorter) readChunk(reader *lineReader, limit int64) ([]string, error) {
+	var lines []string
+	var size int64
+
+	for size < limit && (s.opts.ChunkLines <= 0 || len(lines) < s.opts.ChunkLines) {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return lines, err
+		}
+
+		lines = append(lines, line)
+		size += int64(len(line)) + lineOverhead
+	}
+
+	return lines, nil
+}
+
+func (s *Sorter) sortChunk(lines []string) {
+	// при -s и -u порядок равных строк важен (при -u остается первая из них)
+	if s.opts.Stable || s.opts.Unique {
+		slices.SortStableFunc(lines, s.cmp.Compare)
+		return
+	}
+
+	slices.SortFunc(lines, s.cmp.Compare)
+}
+
+// writeChunk - отсортированный кусок во временный файл
+func (s *Sorter) writeChunk(lines []string) (string, error) {
+	file, err := os.CreateTemp(s.opts.TempDir, "sort_chunk_*.txt")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	for _, line := range lines {
+		if _, err = w.WriteString(line); err == nil {
+			err = w.WriteByte('\n')
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return file.Name(), fmt.Errorf("write temp file: %w", err)
+	}
+
+	return file.Name(), nil
+}
+
+// writeLines - выводим строки, при -u пропуская повторы
+func (s *Sorter) writeLines(w *bufio.Writer, lines []string) error {
+	var last string
+
+	for i, line := range lines {
+		if s.opts.Unique && i > 0 && s.cmp.Equal(last, line) {
+			continue
+		}
+		last = line
+
+		if _, err := w.WriteString(line); err != nil {
+			return err
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// merge - k-way слияние временных файлов: на каждую строку вывода O(log k) сравнений
+func (s *Sorter) merge(w *bufio.Writer, chunks []string) error {
+	h := &mergeHeap{cmp: s.cmp}
+	defer h.close()
+
+	for i, name := range chunks {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+
+		src := &mergeSource{index: i, file: file, reader: bufio.NewReader(file)}
+		h.sources = append(h.sources, src)
+
+		line, err := readLine(src.reader)
+		if errors.Is(err, io.EOF) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		src.line = line
+		h.items = append(h.items, src)
+	}
+
+	heap.Init(h)
+
+	var last string
+	printed := false
+
+	for h.Len() > 0 {
+		src := h.items[0]
+
+		if !s.opts.Unique || !printed || !s.cmp.Equal(last, src.line) {
+			if _, err := w.WriteString(src.line); err != nil {
+				return err
+			}
+			if err := w.WriteByte('\n'); err != nil {
+				return err
+			}
+			last, printed = src.line, true
+		}
+
+		line, err := readLine(src.reader)
+		switch {
+		case errors.Is(err, io.EOF):
+			heap.Pop(h)
+		case err != nil:
+			return err
+		default:
+			src.line = line
+			heap.Fix(h, 0)
+		}
+	}
+
+	return nil
+}
+
+type mergeSource struct {
+	index  int
+	line   string
+	file   *os.File
+	reader *bufio.Reader
+}
+
+// mergeHeap - куча текущих строк кусков. Равные строки берутся из более раннего куска,
+// поэтому слияние сохраняет исходный порядок равных строк
+type mergeHeap struct {
+	cmp     *Comparator
+	items   []*mergeSource
+	sources []*mergeSource
+}
+
+func (h *mergeHeap) Len() int { return len(h.items) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	if r := h.cmp.Compare(h.items[i].line, h.items[j].line); r != 0 {
+		return r < 0
+	}
+
+	return h.items[i].index < h.items[j].index
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *mergeHeap) Push(x any) { h.items = append(h.items, x.(*mergeSource)) }
+
+func (h *mergeHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+
+	return last
+}
+
+func (h *mergeHeap) close() {
+	for _, src := range h.sources {
+		src.file.Close()
+	}
+}
@@ -0,0 +1,161 @@
+package sorting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Key - ключ сортировки в формате GNU sort: -k F[.C][OPTS][,F[.C][OPTS]]
+type Key struct {
+	StartField int // номер поля начала ключа, с 1
+	StartChar  int // символ в поле начала, с 1
+	EndField   int // номер поля конца ключа, 0 - до конца строки
+	EndChar    int // последний символ в поле конца, 0 - до конца поля
+
+	StartBlanks bool // -b для начала ключа: пропускать пробелы в начале поля
+	EndBlanks   bool // -b для конца ключа
+	Numeric     bool // -n
+	Reverse     bool // -r
+	Month       bool // -M
+	Human       bool // -h
+}
+
+// hasOrdering - у ключа заданы свои опции, глобальные флаги он не наследует
+func (k Key) hasOrdering() bool {
+	return k.StartBlanks || k.EndBlanks || k.Numeric || k.Reverse || k.Month || k.Human
+}
+
+// inherit - ключ без своих опций берет глобальные
+func (k Key) inherit(global Key) Key {
+	if k.hasOrdering() {
+		return k
+	}
+
+	k.StartBlanks = global.StartBlanks
+	k.EndBlanks = global.EndBlanks
+	k.Numeric = global.Numeric
+	k.Reverse = global.Reverse
+	k.Month = global.Month
+	k.Human = global.Human
+
+	return k
+}
+
+// ParseKey - разбираем ключ вида 2, 2,3, 2.3,4.5, 2,3n, 1.2b,1.4r
+func ParseKey(spec string) (Key, error) {
+	var key Key
+
+	start, end, hasEnd := strings.Cut(spec, ",")
+
+	field, char, opts, err := parsePosition(start)
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid key %q: %w", spec, err)
+	}
+	if field == 0 {
+		return Key{}, fmt.Errorf("invalid key %q: field number is zero", spec)
+	}
+	if char == 0 {
+		char = 1
+	}
+	key.StartField, key.StartChar = field, char
+
+	if err = key.applyOptions(opts, true); err != nil {
+		return Key{}, fmt.Errorf("invalid key %q: %w", spec, err)
+	}
+
+	if hasEnd {
+		field, char, opts, err = parsePosition(end)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid key %q: %w", spec, err)
+		}
+		if field == 0 {
+			return Key{}, fmt.Errorf("invalid key %q: field number is zero", spec)
+		}
+		key.EndField, key.EndChar = field, char
+
+		if err = key.applyOptions(opts, false); err != nil {
+			return Key{}, fmt.Errorf("invalid key %q: %w", spec, err)
+		}
+	}
+
+	return key, nil
+}
+
+// parsePosition - F[.C][OPTS]
+func parsePosition(pos string) (field, char int, opts string, err error) {
+	i := 0
+	for i < len(pos) && pos[i] >= '0' && pos[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, 0, "", fmt.Errorf("missing field number in %q", pos)
+	}
+
+	field, err = strconv.Atoi(pos[:i])
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	rest := pos[i:]
+	if strings.HasPrefix(rest, ".") {
+		rest = rest[1:]
+
+		j := 0
+		for j < len(rest) && rest[j] >= '0' && rest[j] <= '9' {
+			j++
+		}
+		if j == 0 {
+			return 0, 0, "", fmt.Errorf("missing character number in %q", pos)
+		}
+
+		char, err = strconv.Atoi(rest[:j])
+		if err != nil {
+			return 0, 0, "", err
+		}
+		rest = rest[j:]
+	}
+
+	return field, char, rest, nil
+}
+
+func (k *Key) applyOptions(opts string, start bool) error {
+	for _, opt := range opts {
+		switch opt {
+		case 'b':
+			if start {
+				k.StartBlanks = true
+			} else {
+				k.EndBlanks = true
+			}
+		case 'n':
+			k.Numeric = true
+		case 'r':
+			k.Reverse = true
+		case 'M':
+			k.Month = true
+		case 'h':
+			k.Human = true
+		default:
+			return fmt.Errorf("unknown option %q", opt)
+		}
+	}
+
+	if k.kinds() > 1 {
+		return fmt.Errorf("options n, M and h are mutually exclusive")
+	}
+
+	return nil
+}
+
+// kinds - сколько способов сравнения включено одновременно
+func (k Key) kinds() int {
+	n := 0
+	for _, on := range []bool{k.Numeric, k.Month, k.Human} {
+		if on {
+			n++
+		}
+	}
+
+	return n
+}
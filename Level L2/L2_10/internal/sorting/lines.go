@@ -0,0 +1,101 @@
+package sorting
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// lineReader - читаем строки из нескольких источников подряд.
+// Последняя строка источника без перевода строки не склеивается с первой строкой следующего
+type lineReader struct {
+	inputs  []io.Reader
+	current *bufio.Reader
+}
+
+func newLineReader(inputs []io.Reader) *lineReader {
+	return &lineReader{inputs: inputs}
+}
+
+// ReadLine - следующая строка без \n, io.EOF когда источники закончились
+func (r *lineReader) ReadLine() (string, error) {
+	for {
+		if r.current == nil {
+			if len(r.inputs) == 0 {
+				return "", io.EOF
+			}
+			r.current = bufio.NewReader(r.inputs[0])
+			r.inputs = r.inputs[1:]
+		}
+
+		line, err := readLine(r.current)
+		if errors.Is(err, io.EOF) {
+			r.current = nil
+			continue
+		}
+
+		return line, err
+	}
+}
+
+// readLine - строка любой длины (в отличие от bufio.Scanner), \r\n тоже отрезается
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && line != "" {
+			return strings.TrimSuffix(line, "\r"), nil
+		}
+		if errors.Is(err, io.EOF) {
+			return "", io.EOF
+		}
+
+		return "", fmt.Errorf("read line: %w", err)
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// DisorderError - строка, нарушающая порядок (для -c)
+type DisorderError struct {
+	Line int
+	Text string
+}
+
+func (e *DisorderError) Error() string {
+	return fmt.Sprintf("%d: disorder: %s", e.Line, e.Text)
+}
+
+// Check - проверяем что входные данные уже отсортированы, возвращаем первую строку не по порядку
+func (s *Sorter) Check(input io.Reader) error {
+	reader := newLineReader([]io.Reader{input})
+
+	prev, err := reader.ReadLine()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for n := 2; ; n++ {
+		line, err := reader.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		r := s.cmp.Compare(prev, line)
+		// при -u равные строки тоже считаются нарушением
+		if r > 0 || (s.opts.Unique && s.cmp.Equal(prev, line)) {
+			return &DisorderError{Line: n, Text: line}
+		}
+
+		prev = line
+	}
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"L2_10/internal/sorting"
+	"bytes"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// randomLines - строки вида "число<TAB>месяц<TAB>размер"
+func randomLines(n int) []string {
+	rnd := rand.New(rand.NewSource(1))
+	months := []string{"Jan", "Feb", "Mar", "Apr", "Dec"}
+	sizes := []string{"1K", "20K", "3M", "512", "2G"}
+
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%d\t%s\t%s\t%d", rnd.Intn(1000), months[rnd.Intn(len(months))], sizes[rnd.Intn(len(sizes))], i)
+	}
+
+	return lines
+}
+
+func mustKeys(t testing.TB, specs ...string) []sorting.Key {
+	t.Helper()
+
+	keys := make([]sorting.Key, 0, len(specs))
+	for _, spec := range specs {
+		key, err := sorting.ParseKey(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func sortString(t testing.TB, lines []string, keys []sorting.Key, cmpOpts sorting.CompareOptions, opts sorting.Options) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	sorter := sorting.New(sorting.NewComparator(keys, cmpOpts), opts)
+	if err := sorter.Sort(&out, strings.NewReader(strings.Join(lines, "\n")+"\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.String()
+}
+
+// TestParallelMatchesSequential - параллельная сортировка кусков дает тот же результат, что и один воркер
+func TestParallelMatchesSequential(t *testing.T) {
+	lines := randomLines(5000)
+	keys := mustKeys(t, "2,2M", "1,1nr", "3,3h")
+	cmpOpts := sorting.CompareOptions{Separator: "\t"}
+
+	want := sortString(t, lines, keys, cmpOpts, sorting.Options{Workers: 1})
+
+	for _, workers := range []int{1, 2, 8} {
+		got := sortString(t, lines, keys, cmpOpts, sorting.Options{Workers: workers, ChunkLines: 97})
+		if got != want {
+			t.Errorf("workers=%d: result differs from in-memory sort", workers)
+		}
+	}
+}
+
+// TestStable - при -s строки с равными ключами идут в исходном порядке, даже через слияние кусков
+func TestStable(t *testing.T) {
+	lines := randomLines(3000)
+	keys := mustKeys(t, "2,2M")
+
+	out := sortString(t, lines, keys, sorting.CompareOptions{Separator: "\t", Stable: true},
+		sorting.Options{Stable: true, Workers: 4, ChunkLines: 50})
+
+	got := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+
+	want := slices.Clone(lines)
+	order := map[string]int{"Jan": 1, "Feb": 2, "Mar": 3, "Apr": 4, "Dec": 12}
+	slices.SortStableFunc(want, func(a, b string) int {
+		return order[strings.Split(a, "\t")[1]] - order[strings.Split(b, "\t")[1]]
+	})
+
+	if !slices.Equal(got, want) {
+		t.Errorf("stable sort changed the order of equal lines")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    sorting.Key
+		wantErr bool
+	}{
+		{spec: "2", want: sorting.Key{StartField: 2, StartChar: 1}},
+		{spec: "2,3n", want: sorting.Key{StartField: 2, StartChar: 1, EndField: 3, Numeric: true}},
+		{spec: "1.2b,1.4r", want: sorting.Key{StartField: 1, StartChar: 2, EndField: 1, EndChar: 4, StartBlanks: true, Reverse: true}},
+		{spec: "0", wantErr: true},
+		{spec: "1nM", wantErr: true},
+		{spec: "1x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := sorting.ParseKey(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKey(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseKey(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+// legacySortLines - сортировка из первой версии L2_10: одна колонка и sort.Slice, для сравнения в бенчмарках
+func legacySortLines(lines []string, column int, numeric, reverse bool) {
+	getColumn := func(line string) string {
+		cols := strings.Split(line, "\t")
+		if column-1 < len(cols) {
+			return cols[column-1]
+		}
+		return ""
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		col1, col2 := getColumn(lines[i]), getColumn(lines[j])
+
+		if numeric {
+			ai, errA := strconv.Atoi(col1)
+			bi, errB := strconv.Atoi(col2)
+			if errA == nil && errB == nil {
+				if reverse {
+					return ai > bi
+				}
+				return ai < bi
+			}
+		}
+
+		if reverse {
+			return col1 > col2
+		}
+
+		return col1 < col2
+	})
+}
+
+func BenchmarkLegacySortLines(b *testing.B) {
+	lines := randomLines(100_000)
+
+	for b.Loop() {
+		legacySortLines(slices.Clone(lines), 1, true, false)
+	}
+}
+
+func BenchmarkComparatorInMemory(b *testing.B) {
+	lines := randomLines(100_000)
+	cmp := sorting.NewComparator(mustKeys(b, "1,1n"), sorting.CompareOptions{Separator: "\t"})
+
+	for b.Loop() {
+		slices.SortFunc(slices.Clone(lines), cmp.Compare)
+	}
+}
+
+// BenchmarkExternalSort - сортировка с кусками на диске при разном числе воркеров
+func BenchmarkExternalSort(b *testing.B) {
+	lines := randomLines(200_000)
+	input := strings.Join(lines, "\n") + "\n"
+	keys := mustKeys(b, "2,2M", "1,1n")
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			sorter := sorting.New(sorting.NewComparator(keys, sorting.CompareOptions{Separator: "\t"}), sorting.Options{
+				MemoryBytes: 2 << 20,
+				TempDir:     b.TempDir(),
+				Workers:     workers,
+			})
+
+			for b.Loop() {
+				if err := sorter.Sort(&bytes.Buffer{}, strings.NewReader(input)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
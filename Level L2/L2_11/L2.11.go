@@ -1,37 +1,97 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"slices"
+	"io"
+	"log"
+	"os"
+	"strings"
 )
 
-// groupAnagrams - нахождение анаграмм
-func groupAnagrams(strings []string) [][]string {
-	//создаем мапу для анаграмм
-	anagramsMap := make(map[string][]string, len(strings))
-	//проходимся по строкам сортируем и создаем ключ со значениями
-	for _, str := range strings {
-		chars := []rune(str)
-		slices.Sort(chars)
-		key := string(chars)
-		anagramsMap[key] = append(anagramsMap[key], str)
+func main() {
+	yo := flag.Bool("yo", false, "считать ё и е одной буквой")
+	nfkc := flag.Bool("nfkc", false, "нормализовать слова по Unicode NFKC")
+	asJSON := flag.Bool("json", false, "вывести группы в JSON: {\"первое слово\": [анаграммы]}")
+	maxInMemory := flag.Int("mem", defaultMaxInMemory, "сколько слов группировать в памяти, больше - через временные файлы")
+	flag.Parse()
+
+	// слова из файла или stdin (если файла нет или указан "-")
+	var input io.Reader = os.Stdin
+	if name := flag.Arg(0); name != "" && name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			log.Fatal("Ошибка открытия файла:", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	opts := Options{
+		Normalize:   NormalizeOptions{Yo: *yo, NFKC: *nfkc},
+		MaxInMemory: *maxInMemory,
 	}
 
-	//выводим значения
-	var anagrams [][]string
-	for _, anagram := range anagramsMap {
-		anagrams = append(anagrams, anagram)
+	var err error
+	if *asJSON {
+		err = writeJSON(out, input, opts)
+	} else {
+		err = GroupAnagrams(input, opts, func(group Group) error {
+			_, err := fmt.Fprintf(out, "%s: [%s]\n", group.Key, strings.Join(group.Words, " "))
+			return err
+		})
 	}
 
-	return anagrams
+	if err != nil {
+		out.Flush()
+		log.Fatal(err)
+	}
 }
 
-func main() {
-	anagrams := groupAnagrams([]string{"пятак", "пятка", "тяпка", "листок", "слиток", "столик", "стол"})
+// writeJSON - пишем объект группа -> слова потоково, не собирая все группы в памяти
+func writeJSON(w io.Writer, input io.Reader, opts Options) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+
+	first := true
+	err := GroupAnagrams(input, opts, func(group Group) error {
+		key, err := json.Marshal(group.Key)
+		if err != nil {
+			return err
+		}
+
+		words, err := json.Marshal(group.Words)
+		if err != nil {
+			return err
+		}
+
+		sep := ",\n  "
+		if first {
+			sep, first = "\n  ", false
+		}
 
-	for i := 0; i < len(anagrams); i++ {
-		if len(anagrams[i]) != 1 {
-			fmt.Printf("%v: %v\n", anagrams[i][0], anagrams[i])
+		_, err = fmt.Fprintf(w, "%s%s: %s", sep, key, words)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if !first {
+		_, err = io.WriteString(w, "\n")
+		if err != nil {
+			return err
 		}
 	}
+
+	_, err = io.WriteString(w, "}\n")
+
+	return err
 }
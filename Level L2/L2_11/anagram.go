@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"slices"
+)
+
+// defaultMaxInMemory - сколько слов группируем в памяти, больше - раскладываем по временным файлам
+const defaultMaxInMemory = 1_000_000
+
+// Options - настройки группировки
+type Options struct {
+	Normalize NormalizeOptions
+	// MaxInMemory - порог слов, после которого группировка идет через временные файлы
+	MaxInMemory int
+	// Partitions - на сколько временных файлов делим слова при большом словаре
+	Partitions int
+	// TempDir - каталог временных файлов, пустой - os.TempDir()
+	TempDir string
+}
+
+// Group - множество анаграмм, ключ - первое встретившееся слово группы
+type Group struct {
+	Key   string
+	Words []string // отсортированы, без повторов
+}
+
+// record - слово и его порядковый номер во входных данных
+type record struct {
+	seq  int
+	word string
+}
+
+// GroupAnagrams - читаем слова из r и передаем в fn группы анаграмм из двух и более слов
+// в порядке первого появления. Память ограничена: большой словарь группируется по частям
+func GroupAnagrams(r io.Reader, opts Options, fn func(Group) error) error {
+	if opts.MaxInMemory <= 0 {
+		opts.MaxInMemory = defaultMaxInMemory
+	}
+	if opts.Partitions <= 0 {
+		opts.Partitions = 64
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(bufio.ScanWords)
+
+	var records []record
+	seq := 0
+
+	for len(records) < opts.MaxInMemory && scanner.Scan() {
+		records = append(records, record{seq: seq, word: Normalize(scanner.Text(), opts.Normalize)})
+		seq++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// весь словарь поместился в память
+	if len(records) < opts.MaxInMemory {
+		for _, group := range groupRecords(records) {
+			if err := fn(group.Group); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return groupPartitioned(scanner, records, seq, opts, fn)
+}
+
+// seqGroup - группа и номер первого слова, по нему группы идут в выводе
+type seqGroup struct {
+	Group
+	seq int
+}
+
+// groupRecords - группы анаграмм среди записей, упорядоченные по первому появлению
+func groupRecords(records []record) []seqGroup {
+	type groupState struct {
+		seq   int
+		key   string
+		words map[string]struct{}
+	}
+
+	groups := make(map[string]*groupState)
+
+	for _, rec := range records {
+		sig := signature(rec.word)
+
+		state, ok := groups[sig]
+		if !ok {
+			state = &groupState{seq: rec.seq, key: rec.word, words: make(map[string]struct{})}
+			groups[sig] = state
+		}
+		// записи из временного файла могут идти не по порядку
+		if rec.seq < state.seq {
+			state.seq, state.key = rec.seq, rec.word
+		}
+		state.words[rec.word] = struct{}{}
+	}
+
+	var result []seqGroup
+	for _, state := range groups {
+		// одиночки без анаграмм не выводим
+		if len(state.words) < 2 {
+			continue
+		}
+
+		words := make([]string, 0, len(state.words))
+		for word := range state.words {
+			words = append(words, word)
+		}
+		slices.Sort(words)
+
+		result = append(result, seqGroup{Group: Group{Key: state.key, Words: words}, seq: state.seq})
+	}
+
+	slices.SortFunc(result, func(a, b seqGroup) int { return a.seq - b.seq })
+
+	return result
+}
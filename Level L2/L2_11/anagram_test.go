@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		opts NormalizeOptions
+		want string
+	}{
+		{name: "lower case", word: "ЛиСтОк", want: "листок"},
+		{name: "yo kept", word: "Ёлка", want: "ёлка"},
+		{name: "yo", word: "Ёлка", opts: NormalizeOptions{Yo: true}, want: "елка"},
+		{name: "ligature without nfkc", word: "ﬁle", want: "ﬁle"},
+		{name: "ligature", word: "ﬁle", opts: NormalizeOptions{NFKC: true}, want: "file"},
+		{name: "fullwidth", word: "ＴＯＰ", opts: NormalizeOptions{NFKC: true}, want: "top"},
+		{name: "combining mark", word: "cafe\u0301", opts: NormalizeOptions{NFKC: true}, want: "caf\u00e9"},
+		// е с диерезисом и и с краткой после NFKC - одна буква ё и й
+		{name: "decomposed yo", word: "е\u0308ж", opts: NormalizeOptions{NFKC: true, Yo: true}, want: "еж"},
+		{name: "decomposed yo without nfkc", word: "е\u0308ж", opts: NormalizeOptions{Yo: true}, want: "е\u0308ж"},
+		{name: "decomposed short i", word: "и\u0306од", opts: NormalizeOptions{NFKC: true}, want: "йод"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.word, tt.opts); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+// groups - все группы GroupAnagrams по порядку
+func groups(t *testing.T, input string, opts Options) []Group {
+	t.Helper()
+
+	var result []Group
+	err := GroupAnagrams(strings.NewReader(input), opts, func(group Group) error {
+		result = append(result, group)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+func TestGroupAnagrams(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  NormalizeOptions
+		want  []Group
+	}{
+		{
+			name:  "groups in order of first word",
+			input: "пятак пятка тяпка листок слиток столик стол",
+			want: []Group{
+				{Key: "пятак", Words: []string{"пятак", "пятка", "тяпка"}},
+				{Key: "листок", Words: []string{"листок", "слиток", "столик"}},
+			},
+		},
+		{
+			name:  "case and duplicates",
+			input: "Кот ток КОТ окт",
+			want:  []Group{{Key: "кот", Words: []string{"кот", "окт", "ток"}}},
+		},
+		{
+			name:  "ligature without nfkc",
+			input: "ﬁle lief",
+		},
+		{
+			name:  "ligature with nfkc",
+			input: "ﬁle lief",
+			opts:  NormalizeOptions{NFKC: true},
+			want:  []Group{{Key: "file", Words: []string{"file", "lief"}}},
+		},
+		{
+			name:  "fullwidth with nfkc",
+			input: "ＴＯＰ pot",
+			opts:  NormalizeOptions{NFKC: true},
+			want:  []Group{{Key: "top", Words: []string{"pot", "top"}}},
+		},
+		{
+			name:  "decomposed yo without nfkc",
+			input: "е\u0308ж же",
+			opts:  NormalizeOptions{Yo: true},
+		},
+		{
+			name:  "decomposed yo with nfkc and yo",
+			input: "е\u0308ж же",
+			opts:  NormalizeOptions{NFKC: true, Yo: true},
+			want:  []Group{{Key: "еж", Words: []string{"еж", "же"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groups(t, tt.input, Options{Normalize: tt.opts})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// dictionary - перемешанный словарь: группы анаграмм, повторы и одиночки
+func dictionary() string {
+	var words []string
+	for i := range 300 {
+		n := strconv.Itoa(i)
+		words = append(words, "a"+n+"b", "b"+n+"a", "single"+n+"x")
+		if i%3 == 0 {
+			words = append(words, "A"+n+"B", n+"ab")
+		}
+	}
+	words = append(words, "пятак", "пятка", "тяпка", "листок", "слиток", "столик", "ﬁle", "lief")
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	rng.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+
+	return strings.Join(words, "\n")
+}
+
+// TestGroupPartitioned - через временные файлы получаются те же группы в том же порядке, что в памяти
+func TestGroupPartitioned(t *testing.T) {
+	input := dictionary()
+	count := len(strings.Fields(input))
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "small threshold", opts: Options{MaxInMemory: 10, Partitions: 4}},
+		{name: "one partition", opts: Options{MaxInMemory: 10, Partitions: 1}},
+		{name: "many partitions", opts: Options{MaxInMemory: 100, Partitions: 64}},
+		// словарь ровно по порогу: в памяти он поместился, но это не видно, пока слова не кончились
+		{name: "threshold equals dictionary", opts: Options{MaxInMemory: count, Partitions: 8}},
+		{name: "with nfkc", opts: Options{MaxInMemory: 10, Partitions: 4, Normalize: NormalizeOptions{NFKC: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inMemory := groups(t, input, Options{Normalize: tt.opts.Normalize, MaxInMemory: count + 1})
+			if len(inMemory) == 0 {
+				t.Fatal("no groups in memory")
+			}
+
+			opts := tt.opts
+			opts.TempDir = t.TempDir()
+			partitioned := groups(t, input, opts)
+
+			if !reflect.DeepEqual(partitioned, inMemory) {
+				t.Errorf("partitioned groups differ:\ngot  %v\nwant %v", partitioned, inMemory)
+			}
+
+			// временные файлы удалены
+			entries, err := os.ReadDir(opts.TempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("temp files left: %v", entries)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  string
+	}{
+		{
+			name:  "groups",
+			input: "пятак пятка тяпка листок слиток столик стол",
+			want:  "{\n  \"пятак\": [\"пятак\",\"пятка\",\"тяпка\"],\n  \"листок\": [\"листок\",\"слиток\",\"столик\"]\n}\n",
+		},
+		{
+			// json.Marshal экранирует и HTML-символы
+			name:  "escaping",
+			input: `a"b b"a <ab> >ab<`,
+			want:  "{\n  \"a\\\"b\": [\"a\\\"b\",\"b\\\"a\"],\n  \"\\u003cab\\u003e\": [\"\\u003cab\\u003e\",\"\\u003eab\\u003c\"]\n}\n",
+		},
+		{
+			name:  "no groups",
+			input: "стол стул",
+			want:  "{}\n",
+		},
+		{
+			name:  "partitioned",
+			input: "пятак пятка тяпка листок слиток столик стол",
+			opts:  Options{MaxInMemory: 2, Partitions: 3},
+			want:  "{\n  \"пятак\": [\"пятак\",\"пятка\",\"тяпка\"],\n  \"листок\": [\"листок\",\"слиток\",\"столик\"]\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.TempDir = t.TempDir()
+
+			var out strings.Builder
+			if err := writeJSON(&out, strings.NewReader(tt.input), opts); err != nil {
+				t.Fatal(err)
+			}
+
+			got := out.String()
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			var decoded map[string][]string
+			if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+				t.Errorf("output is not valid JSON: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+module L2_11
+
+go 1.24.5
+
+require golang.org/x/text v0.29.0
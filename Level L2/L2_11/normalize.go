@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeOptions - как приводить слова к общему виду перед поиском анаграмм
+type NormalizeOptions struct {
+	Yo   bool // ё -> е
+	NFKC bool // Unicode NFKC: лигатуры, полноширинные буквы, составные символы
+}
+
+// Normalize - слово в нижнем регистре с выбранной нормализацией
+func Normalize(word string, opts NormalizeOptions) string {
+	if opts.NFKC {
+		word = norm.NFKC.String(word)
+	}
+
+	word = strings.ToLower(word)
+
+	if opts.Yo {
+		word = strings.ReplaceAll(word, "ё", "е")
+	}
+
+	return word
+}
+
+// signature - буквы слова по порядку, одинаковая у всех анаграмм
+func signature(word string) string {
+	chars := []rune(word)
+	slices.Sort(chars)
+
+	return string(chars)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// groupPartitioned - словарь не влез в память: раскладываем слова по временным файлам по хешу сигнатуры
+// (все анаграммы попадают в один файл), группируем каждый файл отдельно и сливаем группы по порядку
+func groupPartitioned(scanner *bufio.Scanner, records []record, seq int, opts Options, fn func(Group) error) (err error) {
+	var tempFiles []string
+	defer func() {
+		for _, name := range tempFiles {
+			if removeErr := os.Remove(name); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) && err == nil {
+				err = removeErr
+			}
+		}
+	}()
+
+	createTemp := func(pattern string) (*os.File, error) {
+		file, err := os.CreateTemp(opts.TempDir, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("create temp file: %w", err)
+		}
+		tempFiles = append(tempFiles, file.Name())
+
+		return file, nil
+	}
+
+	// 1. раскладываем слова по частям
+	files := make([]*os.File, opts.Partitions)
+	writers := make([]*bufio.Writer, opts.Partitions)
+	defer func() {
+		for _, file := range files {
+			if file != nil {
+				file.Close()
+			}
+		}
+	}()
+
+	for i := range files {
+		if files[i], err = createTemp("anagram_part_*.txt"); err != nil {
+			return err
+		}
+		writers[i] = bufio.NewWriter(files[i])
+	}
+
+	write := func(rec record) error {
+		h := fnv.New32a()
+		h.Write([]byte(signature(rec.word)))
+		w := writers[h.Sum32()%uint32(opts.Partitions)]
+
+		_, err := fmt.Fprintf(w, "%d\t%s\n", rec.seq, rec.word)
+
+		return err
+	}
+
+	for _, rec := range records {
+		if err = write(rec); err != nil {
+			return err
+		}
+	}
+	records = nil
+
+	for ; scanner.Scan(); seq++ {
+		if err = write(record{seq: seq, word: Normalize(scanner.Text(), opts.Normalize)}); err != nil {
+			return err
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+
+	for i, w := range writers {
+		if err = w.Flush(); err != nil {
+			return err
+		}
+		if err = files[i].Close(); err != nil {
+			return err
+		}
+		files[i] = nil
+	}
+
+	// 2. группируем каждую часть в памяти, группы пишем в файл результата по порядку
+	results := make([]string, 0, opts.Partitions)
+	for _, name := range tempFiles[:opts.Partitions] {
+		result, err := groupPartition(name, createTemp)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	// 3. сливаем результаты частей по номеру первого слова группы
+	return mergeGroups(results, fn)
+}
+
+// partitionGroup - группа в файле результата части
+type partitionGroup struct {
+	Seq   int      `json:"seq"`
+	Key   string   `json:"key"`
+	Words []string `json:"words"`
+}
+
+// groupPartition - группируем одну часть, возвращаем имя файла с ее группами
+func groupPartition(name string, createTemp func(string) (*os.File, error)) (string, error) {
+	in, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+
+	var records []record
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		seqText, word, ok := strings.Cut(scanner.Text(), "\t")
+		seq, convErr := strconv.Atoi(seqText)
+		if !ok || convErr != nil {
+			in.Close()
+			return "", fmt.Errorf("corrupted temp file %s: %q", name, scanner.Text())
+		}
+		records = append(records, record{seq: seq, word: word})
+	}
+	in.Close()
+	if err = scanner.Err(); err != nil {
+		return "", err
+	}
+
+	// часть больше не нужна, освобождаем диск сразу
+	if err = os.Remove(name); err != nil {
+		return "", err
+	}
+
+	out, err := createTemp("anagram_groups_*.jsonl")
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	w := bufio.NewWriter(out)
+	encoder := json.NewEncoder(w)
+
+	for _, group := range groupRecords(records) {
+		if err = encoder.Encode(partitionGroup{Seq: group.seq, Key: group.Key, Words: group.Words}); err != nil {
+			return "", err
+		}
+	}
+
+	if err = w.Flush(); err != nil {
+		return "", err
+	}
+
+	return out.Name(), out.Close()
+}
+
+// groupSource - текущая группа файла результата
+type groupSource struct {
+	group   partitionGroup
+	decoder *json.Decoder
+}
+
+type groupHeap []*groupSource
+
+func (h groupHeap) Len() int           { return len(h) }
+func (h groupHeap) Less(i, j int) bool { return h[i].group.Seq < h[j].group.Seq }
+func (h groupHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *groupHeap) Push(x any)        { *h = append(*h, x.(*groupSource)) }
+
+func (h *groupHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return last
+}
+
+// mergeGroups - k-way слияние файлов результатов через кучу
+func mergeGroups(results []string, fn func(Group) error) error {
+	h := &groupHeap{}
+
+	for _, name := range results {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		src := &groupSource{decoder: json.NewDecoder(bufio.NewReader(file))}
+		if err = src.decoder.Decode(&src.group); errors.Is(err, io.EOF) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		*h = append(*h, src)
+	}
+
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		src := (*h)[0]
+		if err := fn(Group{Key: src.group.Key, Words: src.group.Words}); err != nil {
+			return err
+		}
+
+		src.group = partitionGroup{}
+		err := src.decoder.Decode(&src.group)
+		switch {
+		case errors.Is(err, io.EOF):
+			heap.Pop(h)
+		case err != nil:
+			return err
+		default:
+			heap.Fix(h, 0)
+		}
+	}
+
+	return nil
+}
@@ -2,29 +2,80 @@ package main
 
 import (
 	"L2_12/internal/cli"
+	"L2_12/internal/render"
 	"L2_12/internal/search"
-	"bufio"
-	"log"
+	"fmt"
 	"os"
 )
 
+// Коды выхода как у grep
+const (
+	exitMatch   = 0 // нашлась хотя бы одна строка
+	exitNoMatch = 1 // ничего не нашлось
+	exitError   = 2 // ошибка, даже если что-то нашлось
+)
+
 func main() {
-	options, pattern, filename := cli.ParseOptions()
-
-	var scanner *bufio.Scanner
-
-	if filename == "" || filename == "-" {
-		// Если файла нет или указан "-", читаем из stdin
-		scanner = bufio.NewScanner(os.Stdin)
-	} else {
-		// Иначе открываем файл
-		file, err := os.Open(filename)
-		if err != nil {
-			log.Fatal("Ошибка открытия файла:", err)
-		}
-		defer file.Close()
-		scanner = bufio.NewScanner(file)
+	os.Exit(run())
+}
+
+func run() int {
+	options, files, err := cli.ParseOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "grep:", err)
+		return exitError
+	}
+
+	matcher, err := search.NewMatcher(options.Patterns, &options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "grep:", err)
+		return exitError
+	}
+
+	// имя файла выводим, если файлов несколько или -r обходит каталог; -H и -h задают это явно
+	options.WithFilename = !options.NoFilename && (options.WithFilename || len(files) > 1 ||
+		options.Recursive && (len(files) == 0 || isDir(files[0])))
+
+	matched, failed := search.NewSearcher(&options, matcher).Run(files, newRenderer(&options), os.Stderr)
+
+	switch {
+	case failed:
+		return exitError
+	case matched:
+		return exitMatch
+	default:
+		return exitNoMatch
 	}
+}
+
+// newRenderer - --json или текст в формате grep, с подсветкой по --color
+func newRenderer(options *cli.Options) search.Renderer {
+	if options.JSON {
+		return render.NewJSON(os.Stdout)
+	}
+
+	return render.NewText(os.Stdout, render.TextOptions{
+		LineNum:      options.LineNum,
+		WithFilename: options.WithFilename,
+		OnlyMatching: options.OnlyMatching,
+		Color:        useColor(options.Color),
+	})
+}
+
+// useColor - при auto подсвечиваем, только если stdout - терминал, который понимает цвета
+func useColor(mode string) bool {
+	switch mode {
+	case cli.ColorAlways:
+		return true
+	case cli.ColorAuto:
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+	default:
+		return false
+	}
+}
 
-	search.PatternString(scanner, &options, pattern)
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
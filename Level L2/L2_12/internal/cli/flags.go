@@ -1,23 +1,92 @@
 package cli
 
 import (
+	"errors"
 	"flag"
-	"log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
+// boolFlags и valueFlags - однобуквенные флаги без значения и со значением
+const (
+	boolFlags  = "civFnwxorlLHh"
+	valueFlags = "ABCefm"
+)
+
+// longValueFlags - длинные флаги со значением, которое может идти отдельным аргументом
+var longValueFlags = []string{"include", "exclude"}
+
 // Options - структура для хранения данных флагов
 type Options struct {
-	After   int  // -A
-	Before  int  // -B
-	Count   bool // -c
-	Ignore  bool // -i
-	Invert  bool // -v
-	Fixed   bool // -F
-	LineNum bool // -n
+	After        int      // -A
+	Before       int      // -B
+	Count        bool     // -c
+	Ignore       bool     // -i
+	Invert       bool     // -v
+	Fixed        bool     // -F
+	LineNum      bool     // -n
+	Word         bool     // -w
+	Line         bool     // -x
+	OnlyMatching bool     // -o
+	Patterns     []string // -e, -f или первый аргумент
+
+	Recursive    bool     // -r
+	FilesWith    bool     // -l
+	FilesWithout bool     // -L
+	WithFilename bool     // -H
+	NoFilename   bool     // -h
+	MaxCount     int      // -m, -1 - без ограничения
+	Include      []string // --include
+	Exclude      []string // --exclude
+
+	Color string // --color: never, always или auto
+	JSON  bool   // --json
+}
+
+// Значения --color
+const (
+	ColorNever  = "never"
+	ColorAlways = "always"
+	ColorAuto   = "auto"
+)
+
+// colorFlag - --color без значения означает auto, как в GNU grep
+type colorFlag struct {
+	value string
+}
+
+func (c *colorFlag) String() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.value
+}
+
+func (c *colorFlag) Set(s string) error {
+	switch s {
+	case "true":
+		c.value = ColorAuto
+	case ColorNever, ColorAlways, ColorAuto:
+		c.value = s
+	default:
+		return fmt.Errorf("неверное значение --color %q: ожидается never, always или auto", s)
+	}
+
+	return nil
 }
 
-// ParseOptions - подключаем флаги
-func ParseOptions() (Options, string, string) {
+// IsBoolFlag - разрешаем писать --color без =значения
+func (c *colorFlag) IsBoolFlag() bool { return true }
+
+// ParseOptions - подключаем флаги, возвращаем опции и файлы (пустой список - stdin или "." при -r)
+func ParseOptions() (Options, []string, error) {
+	var patterns, include, exclude []string
+	patternsSet := false
+
 	// Флаги
 	flagA := flag.Int("A", 0, "вывести N строк после найденной строки")
 	flagB := flag.Int("B", 0, "вывести N строк до найденной строки")
@@ -27,31 +96,195 @@ func ParseOptions() (Options, string, string) {
 	flagV := flag.Bool("v", false, "инвертировать фильтр")
 	flagF := flag.Bool("F", false, "воспринимать шаблон как фиксированную строку")
 	flagN := flag.Bool("n", false, "выводить номер строки перед каждой найденной строкой")
+	flagW := flag.Bool("w", false, "шаблон должен совпадать с целым словом")
+	flagX := flag.Bool("x", false, "шаблон должен совпадать со всей строкой")
+	flagO := flag.Bool("o", false, "выводить только совпавшие части строк")
+	flagR := flag.Bool("r", false, "искать рекурсивно в каталогах")
+	flagL := flag.Bool("l", false, "выводить только имена файлов с совпадениями")
+	flagLWithout := flag.Bool("L", false, "выводить только имена файлов без совпадений")
+	flagH := flag.Bool("H", false, "выводить имя файла перед каждой строкой")
+	flagHNo := flag.Bool("h", false, "не выводить имя файла")
+	flagM := flag.Int("m", -1, "остановиться после N подходящих строк")
+	color := &colorFlag{value: ColorNever}
+	flag.Var(color, "color", "подсветка совпадений: never, always или auto")
+	flagJSON := flag.Bool("json", false, "выводить события в формате JSON Lines")
+	flag.Func("include", "искать только в файлах, имя которых подходит под шаблон", func(s string) error {
+		include = append(include, s)
+		return nil
+	})
+	flag.Func("exclude", "пропускать файлы, имя которых подходит под шаблон", func(s string) error {
+		exclude = append(exclude, s)
+		return nil
+	})
+	flag.Func("e", "шаблон поиска, можно указать несколько раз", func(s string) error {
+		patterns = append(patterns, s)
+		patternsSet = true
+		return nil
+	})
+	flag.Func("f", "файл с шаблонами, по одному на строку", func(name string) error {
+		filePatterns, err := readPatterns(name)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, filePatterns...)
+		patternsSet = true
+		return nil
+	})
 
-	flag.Parse()
+	// ошибка разбора обрабатывается самим flag.CommandLine (ExitOnError)
+	_ = flag.CommandLine.Parse(expandShortFlags(os.Args[1:]))
 
-	// Проверка аргументов
+	// Проверка аргументов: без -e и -f первый аргумент - шаблон
 	args := flag.Args()
-	if len(args) < 2 {
-		log.Fatal("нужно передать шаблон и имя файла")
+	if !patternsSet {
+		if len(args) < 1 {
+			return Options{}, nil, errors.New("нужно передать шаблон")
+		}
+		patterns = []string{args[0]}
+		args = args[1:]
 	}
-	pattern := args[0]
-	filename := args[1]
 
+	for _, glob := range slices.Concat(include, exclude) {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return Options{}, nil, fmt.Errorf("неверный шаблон имени файла %q: %w", glob, err)
+		}
+	}
+
+	// -C задает контекст по умолчанию, явные -A и -B важнее, как в GNU grep
 	after, before := *flagA, *flagB
-	if *flagC > 0 {
-		// Превращаем -С в -А и -В
-		after = *flagC
-		before = *flagC
+	if isSet("C") {
+		if !isSet("A") {
+			after = *flagC
+		}
+		if !isSet("B") {
+			before = *flagC
+		}
 	}
 
 	return Options{
-		After:   after,
-		Before:  before,
-		Count:   *flagCCount,
-		Ignore:  *flagI,
-		Invert:  *flagV,
-		Fixed:   *flagF,
-		LineNum: *flagN,
-	}, pattern, filename
+		After:        after,
+		Before:       before,
+		Count:        *flagCCount,
+		Ignore:       *flagI,
+		Invert:       *flagV,
+		Fixed:        *flagF,
+		LineNum:      *flagN,
+		Word:         *flagW,
+		Line:         *flagX,
+		OnlyMatching: *flagO,
+		Patterns:     patterns,
+		Recursive:    *flagR,
+		FilesWith:    *flagL,
+		FilesWithout: *flagLWithout,
+		WithFilename: *flagH,
+		NoFilename:   *flagHNo,
+		MaxCount:     *flagM,
+		Include:      include,
+		Exclude:      exclude,
+		Color:        color.value,
+		JSON:         *flagJSON,
+	}, args, nil
+}
+
+// expandShortFlags - приводим GNU запись к виду, который понимает пакет flag:
+// -in превращаем в -i -n, -C2 в -C 2, -nA2 в -n -A 2. Как и GNU grep, флаги можно
+// писать после шаблона и файлов: они переносятся вперед, остальное идет после --
+func expandShortFlags(args []string) []string {
+	expanded := make([]string, 0, len(args))
+	var positional []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// после -- флагов нет
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, arg)
+			continue
+		}
+
+		if arg[1] == '-' && slices.Contains(longValueFlags, arg[2:]) && i+1 < len(args) {
+			expanded = append(expanded, arg, args[i+1])
+			i++
+			continue
+		}
+
+		if len(arg) == 2 || arg[1] == '-' || strings.Contains(arg, "=") {
+			expanded = append(expanded, arg)
+			// значение отдельного флага (-e -v) может начинаться с минуса, его не разбираем
+			if len(arg) == 2 && strings.IndexByte(valueFlags, arg[1]) >= 0 && i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
+			}
+			continue
+		}
+
+		name := arg[1:]
+		if !knownShortFlags(name) {
+			// неизвестная буква - отдаем как есть, flag сообщит об ошибке
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		for j := 0; j < len(name); j++ {
+			c := name[j]
+			expanded = append(expanded, "-"+string(c))
+
+			if strings.IndexByte(valueFlags, c) < 0 {
+				continue
+			}
+			// остаток аргумента - значение флага, а если его нет - следующий аргумент
+			if j+1 < len(name) {
+				expanded = append(expanded, name[j+1:])
+			} else if i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
+			}
+			break
+		}
+	}
+
+	return append(append(expanded, "--"), positional...)
+}
+
+// knownShortFlags - все буквы до первого флага со значением известны
+func knownShortFlags(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if strings.IndexByte(valueFlags, name[i]) >= 0 {
+			return true
+		}
+		if strings.IndexByte(boolFlags, name[i]) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isSet - флаг явно указан в командной строке
+func isSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// readPatterns - шаблоны из файла для -f, пустой файл не совпадает ни с чем
+func readPatterns(name string) ([]string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
 }
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"L2_12/internal/search"
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// jsonSpan - совпадение: байтовые смещения в строке и сам текст
+type jsonSpan struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// jsonLine - событие match или context
+type jsonLine struct {
+	Type    string     `json:"type"`
+	File    string     `json:"file"`
+	Line    int        `json:"line"`
+	Text    string     `json:"text"`
+	Matches []jsonSpan `json:"matches"`
+}
+
+// jsonCount - событие count (-c)
+type jsonCount struct {
+	Type  string `json:"type"`
+	File  string `json:"file"`
+	Count int    `json:"count"`
+}
+
+// jsonFile - событие file (-l, -L)
+type jsonFile struct {
+	Type string `json:"type"`
+	File string `json:"file"`
+}
+
+// JSON - события в формате JSON Lines, по объекту на строку. Разделители групп не выводятся:
+// смежность строк видна по номерам
+type JSON struct {
+	out *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewJSON - конструктор, вывод буферизуется до Flush
+func NewJSON(w io.Writer) *JSON {
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	return &JSON{out: out, enc: enc}
+}
+
+// Render - одно событие поиска в виде JSON-объекта
+func (j *JSON) Render(event search.Event) error {
+	switch event.Kind {
+	case search.KindMatch, search.KindContext:
+		matches := make([]jsonSpan, 0, len(event.Matches))
+		for _, span := range event.Matches {
+			matches = append(matches, jsonSpan{Start: span.Start, End: span.End, Text: event.Text[span.Start:span.End]})
+		}
+
+		return j.enc.Encode(jsonLine{
+			Type:    event.Kind.String(),
+			File:    event.File,
+			Line:    event.Line,
+			Text:    event.Text,
+			Matches: matches,
+		})
+
+	case search.KindCount:
+		return j.enc.Encode(jsonCount{Type: event.Kind.String(), File: event.File, Count: event.Count})
+
+	case search.KindFile:
+		return j.enc.Encode(jsonFile{Type: event.Kind.String(), File: event.File})
+	}
+
+	return nil
+}
+
+// Flush - сбрасываем буфер, ошибки записи всплывают здесь
+func (j *JSON) Flush() error {
+	return j.out.Flush()
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"L2_12/internal/search"
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// Цвета GNU grep по умолчанию (GREP_COLORS='ms=01;31:mc=01;31:fn=35:ln=32:se=36')
+const (
+	colorMatch     = "01;31" // совпадение
+	colorFilename  = "35"    // имя файла
+	colorLineNum   = "32"    // номер строки
+	colorSeparator = "36"    // ':', '-' и "--"
+)
+
+// groupSeparator - разделитель несмежных групп контекста, как в GNU grep
+const groupSeparator = "--"
+
+// TextOptions - формат текстового вывода
+type TextOptions struct {
+	LineNum      bool // -n
+	WithFilename bool // -H или несколько файлов
+	OnlyMatching bool // -o
+	Color        bool // --color: ANSI-подсветка как у GNU grep
+}
+
+// Text - вывод в формате GNU grep: [файл:]N:совпадение, [файл-]N-контекст, -- между группами
+type Text struct {
+	out  *bufio.Writer
+	opts TextOptions
+}
+
+// NewText - конструктор, вывод буферизуется до Flush
+func NewText(w io.Writer, opts TextOptions) *Text {
+	return &Text{out: bufio.NewWriter(w), opts: opts}
+}
+
+// Render - одно событие поиска в текстовом виде
+func (t *Text) Render(event search.Event) error {
+	switch event.Kind {
+	case search.KindSeparator:
+		t.colored(colorSeparator, groupSeparator)
+		t.out.WriteByte('\n')
+
+	case search.KindFile:
+		t.colored(colorFilename, event.File)
+		t.out.WriteByte('\n')
+
+	case search.KindCount:
+		if t.opts.WithFilename {
+			t.colored(colorFilename, event.File)
+			t.colored(colorSeparator, ":")
+		}
+		t.out.WriteString(strconv.Itoa(event.Count))
+		t.out.WriteByte('\n')
+
+	case search.KindMatch, search.KindContext:
+		t.line(event)
+	}
+
+	return nil
+}
+
+// Flush - сбрасываем буфер, ошибки записи всплывают здесь
+func (t *Text) Flush() error {
+	return t.out.Flush()
+}
+
+func (t *Text) line(event search.Event) {
+	sep := ":"
+	if event.Kind == search.KindContext {
+		sep = "-"
+	}
+
+	// при -o выводятся только совпадения подходящих строк, каждое на своей строке
+	if t.opts.OnlyMatching {
+		if event.Kind == search.KindContext {
+			return
+		}
+		for _, span := range event.Matches {
+			t.prefix(event, sep)
+			t.colored(colorMatch, event.Text[span.Start:span.End])
+			t.out.WriteByte('\n')
+		}
+		return
+	}
+
+	t.prefix(event, sep)
+
+	pos := 0
+	for _, span := range event.Matches {
+		t.out.WriteString(event.Text[pos:span.Start])
+		t.colored(colorMatch, event.Text[span.Start:span.End])
+		pos = span.End
+	}
+	t.out.WriteString(event.Text[pos:])
+	t.out.WriteByte('\n')
+}
+
+func (t *Text) prefix(event search.Event, sep string) {
+	if t.opts.WithFilename {
+		t.colored(colorFilename, event.File)
+		t.colored(colorSeparator, sep)
+	}
+	if t.opts.LineNum {
+		t.colored(colorLineNum, strconv.Itoa(event.Line))
+		t.colored(colorSeparator, sep)
+	}
+}
+
+// colored - текст в SGR-последовательностях GNU grep, без --color как есть
+func (t *Text) colored(color, text string) {
+	if !t.opts.Color {
+		t.out.WriteString(text)
+		return
+	}
+
+	t.out.WriteString("\x1b[" + color + "m\x1b[K")
+	t.out.WriteString(text)
+	t.out.WriteString("\x1b[m\x1b[K")
+}
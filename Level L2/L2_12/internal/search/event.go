@@ -0,0 +1,160 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Kind - тип события поиска
+type Kind int
+
+const (
+	// KindMatch - подходящая строка (при -v - строка без совпадений)
+	KindMatch Kind = iota
+	// KindContext - строка контекста -A, -B, -C
+	KindContext
+	// KindSeparator - разрыв между несмежными группами строк ("--" в выводе grep)
+	KindSeparator
+	// KindCount - количество подходящих строк в файле (-c)
+	KindCount
+	// KindFile - имя файла для -l и -L
+	KindFile
+)
+
+var kindNames = [...]string{
+	KindMatch:     "match",
+	KindContext:   "context",
+	KindSeparator: "separator",
+	KindCount:     "count",
+	KindFile:      "file",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+
+	return kindNames[k]
+}
+
+// Span - совпадение в строке, байтовые смещения [Start, End)
+type Span struct {
+	Start int
+	End   int
+}
+
+// Event - результат поиска без привязки к формату вывода
+type Event struct {
+	Kind Kind
+	File string
+	// Line - номер строки с 1, для KindMatch и KindContext
+	Line int
+	Text string
+	// Matches - непустые совпадения шаблона в строке (у контекста они бывают при -v)
+	Matches []Span
+	// Count - для KindCount
+	Count int
+}
+
+// Renderer - получатель событий поиска. События одного файла приходят подряд,
+// файлы - в порядке перечисления, вызовы не параллельны
+type Renderer interface {
+	Render(event Event) error
+}
+
+// Flusher - Renderer с буфером, который сбрасывается перед сообщениями об ошибках в stderr
+type Flusher interface {
+	Flush() error
+}
+
+// errRenderStopped - вывод уже сломался, события файла больше не нужны
+var errRenderStopped = errors.New("вывод остановлен")
+
+// relay - события файла на пути к рендереру. Пока очередь вывода не дошла до файла, события
+// копятся в памяти, а файл в начале очереди пишет прямо в рендерер: так в памяти только
+// файлы, обогнавшие медленный, а stdin выводится по мере чтения
+type relay struct {
+	mu       sync.Mutex
+	renderer Renderer // nil - очередь до файла еще не дошла
+	buffered []Event
+	// flush - после каждого события сбрасываем буфер рендерера, чтобы stdin не ждал EOF
+	flush bool
+	// separate - перед первой строкой файла нужен "--": выше уже были группы других файлов
+	separate bool
+	// err - ошибка рендерера, после нее события не отправляются
+	err error
+}
+
+func (r *relay) Render(event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.renderer == nil && r.err == nil {
+		r.buffered = append(r.buffered, event)
+		return nil
+	}
+
+	return r.forward(event)
+}
+
+// attach - файл дошел до начала очереди: отдаем накопленное и дальше пишем напрямую
+func (r *relay) attach(renderer Renderer, separate bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.renderer, r.separate = renderer, separate
+	for _, event := range r.buffered {
+		if r.forward(event) != nil {
+			break
+		}
+	}
+	r.buffered = nil
+}
+
+// stop - вывод сломался: накопленное выбрасываем, а поиск в файле прерываем
+func (r *relay) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err, r.buffered = errRenderStopped, nil
+}
+
+// failed - ошибка рендерера, nil - все события выведены
+func (r *relay) failed() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if errors.Is(r.err, errRenderStopped) {
+		return nil
+	}
+	return r.err
+}
+
+// caused - err пришла из рендерера или из stop, а не из самого файла
+func (r *relay) caused(err error) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return err != nil && err == r.err
+}
+
+// forward - событие в рендерер, вызывается под mu
+func (r *relay) forward(event Event) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.separate && (event.Kind == KindMatch || event.Kind == KindContext) {
+		r.separate = false
+		r.err = r.renderer.Render(Event{Kind: KindSeparator, File: event.File})
+	}
+	if r.err == nil {
+		r.err = r.renderer.Render(event)
+	}
+	if flusher, ok := r.renderer.(Flusher); ok && r.err == nil && r.flush {
+		r.err = flusher.Flush()
+	}
+
+	return r.err
+}
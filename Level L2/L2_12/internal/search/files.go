@@ -0,0 +1,278 @@
+package search
+
+import (
+	"L2_12/internal/cli"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// stdinName - имя stdin в выводе, как у GNU grep
+	stdinName = "(standard input)"
+	// binaryPeek - больше этого начала файла на NUL не проверяем
+	binaryPeek = 32 << 10
+	// maxLineSize - самая длинная строка, которую может прочитать сканер
+	maxLineSize = 16 << 20
+)
+
+// Result - итог поиска в одном файле, события к этому времени уже ушли в его relay
+type Result struct {
+	Name    string
+	Summary Summary
+	// Binary - в начале файла есть NUL, файл пропущен
+	Binary bool
+	Err    error
+}
+
+// fileJob - файл в очереди; события идут в relay, done получает результат, когда воркер его обработает
+type fileJob struct {
+	path  string
+	relay *relay
+	done  chan Result
+}
+
+// Searcher - параллельный поиск по файлам и каталогам с выводом в порядке их перечисления
+type Searcher struct {
+	options *cli.Options
+	matcher *Matcher
+	workers int
+	stdin   io.Reader
+}
+
+// NewSearcher - конструктор, файлы ищутся в runtime.NumCPU() горутин
+func NewSearcher(options *cli.Options, matcher *Matcher) *Searcher {
+	return &Searcher{options: options, matcher: matcher, workers: runtime.NumCPU(), stdin: os.Stdin}
+}
+
+// Run - ищем во всех путях ("-" - stdin) и отдаем события в renderer. Без путей читаем stdin,
+// а при -r обходим текущий каталог. matched - нашлась хотя бы одна строка, failed - была
+// ошибка (о ней уже написано в stderr)
+func (s *Searcher) Run(paths []string, renderer Renderer, stderr io.Writer) (matched, failed bool) {
+	// очередь вывода ограничена, чтобы быстрые воркеры не уходили далеко вперед медленного файла
+	order := make(chan *fileJob, s.workers*4)
+	jobs := make(chan *fileJob)
+
+	go func() {
+		defer close(jobs)
+		defer close(order)
+
+		switch {
+		case len(paths) > 0:
+			for _, path := range paths {
+				s.enqueue(path, order, jobs)
+			}
+		case s.options.Recursive:
+			// как GNU grep, имена файлов текущего каталога выводим без ./
+			s.walk(".", "", order, jobs)
+		default:
+			s.push("-", order, jobs)
+		}
+	}()
+
+	for range s.workers {
+		go func() {
+			for job := range jobs {
+				job.done <- s.searchFile(job.path, job.relay)
+			}
+		}()
+	}
+
+	grouped := false
+	renderFailed := false
+	for job := range order {
+		// после ошибки вывода только дочитываем очередь, чтобы не оставить висеть горутины
+		if renderFailed {
+			job.relay.stop()
+		} else {
+			// "--" между группами разных файлов, как между группами одного
+			job.relay.attach(renderer, hasContext(s.options) && grouped)
+		}
+
+		result := <-job.done
+		matched = matched || result.Summary.Matches > 0
+		grouped = grouped || result.Summary.Grouped
+
+		if renderFailed {
+			continue
+		}
+
+		if err := job.relay.failed(); err != nil {
+			fmt.Fprintf(stderr, "grep: %v\n", err)
+			failed, renderFailed = true, true
+			continue
+		}
+
+		if result.Err != nil {
+			report(renderer, stderr, result.Name, result.Err)
+			failed = true
+		}
+	}
+
+	if flusher, ok := renderer.(Flusher); ok && !renderFailed {
+		if err := flusher.Flush(); err != nil {
+			fmt.Fprintf(stderr, "grep: %v\n", err)
+			failed = true
+		}
+	}
+
+	return matched, failed
+}
+
+// report - ошибка в stderr, но сначала выводим то, что уже накоплено в буфере рендерера
+func report(renderer Renderer, stderr io.Writer, name string, err error) {
+	if flusher, ok := renderer.(Flusher); ok {
+		flusher.Flush()
+	}
+	fmt.Fprintf(stderr, "grep: %s: %v\n", name, err)
+}
+
+// enqueue - ставим путь в очередь, каталоги при -r обходим в лексикографическом порядке
+func (s *Searcher) enqueue(path string, order, jobs chan<- *fileJob) {
+	if path == "-" {
+		s.push(path, order, jobs)
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		s.fail(path, unwrapPathError(err), order)
+		return
+	}
+
+	if !info.IsDir() {
+		if s.selected(path) {
+			s.push(path, order, jobs)
+		}
+		return
+	}
+
+	if !s.options.Recursive {
+		s.fail(path, errors.New("Is a directory"), order)
+		return
+	}
+
+	// имена внутри каталога строим от пути в том виде, в каком его передали (без завершающих /):
+	// -r foo ./sub выводит ./sub/x, как GNU grep, хотя WalkDir отдает очищенное sub/x
+	s.walk(path, strings.TrimRight(path, "/")+"/", order, jobs)
+}
+
+// walk - обходим каталог root, имя файла в выводе - prefix и путь относительно root
+func (s *Searcher) walk(root, prefix string, order, jobs chan<- *fileJob) {
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if rel, relErr := filepath.Rel(root, name); relErr == nil && rel != "." {
+			name = prefix + rel
+		} else {
+			name = root
+		}
+
+		if err != nil {
+			s.fail(name, unwrapPathError(err), order)
+			return nil
+		}
+
+		// -r, в отличие от -R, не идет по символическим ссылкам внутри каталогов
+		if d.IsDir() || d.Type()&fs.ModeSymlink != 0 || !s.selected(name) {
+			return nil
+		}
+
+		s.push(name, order, jobs)
+		return nil
+	})
+	if err != nil {
+		s.fail(root, err, order)
+	}
+}
+
+func (s *Searcher) push(path string, order, jobs chan<- *fileJob) {
+	job := &fileJob{path: path, relay: &relay{flush: path == "-"}, done: make(chan Result, 1)}
+	order <- job
+	jobs <- job
+}
+
+// fail - ошибка пути попадает в вывод в том же порядке, что и результаты файлов
+func (s *Searcher) fail(path string, err error, order chan<- *fileJob) {
+	job := &fileJob{path: path, relay: &relay{}, done: make(chan Result, 1)}
+	job.done <- Result{Name: path, Err: err}
+	order <- job
+}
+
+// selected - файл проходит --include и --exclude (шаблоны сравниваются с именем без каталога)
+func (s *Searcher) selected(path string) bool {
+	base := filepath.Base(path)
+
+	for _, glob := range s.options.Exclude {
+		if ok, _ := filepath.Match(glob, base); ok {
+			return false
+		}
+	}
+
+	if len(s.options.Include) == 0 {
+		return true
+	}
+
+	for _, glob := range s.options.Include {
+		if ok, _ := filepath.Match(glob, base); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// searchFile - поиск в одном файле, события уходят в events
+func (s *Searcher) searchFile(path string, events *relay) Result {
+	name := path
+	var input io.Reader = s.stdin
+
+	if path == "-" {
+		name = stdinName
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return Result{Name: name, Err: unwrapPathError(err)}
+		}
+		defer file.Close()
+		input = file
+	}
+
+	reader := bufio.NewReaderSize(input, binaryPeek)
+
+	// бинарный файл определяем по NUL в первом прочитанном блоке, как GNU grep. Больше
+	// одного чтения не ждем, иначе stdin из медленного конвейера выводился бы только в конце
+	if _, err := reader.Peek(1); err != nil && !errors.Is(err, io.EOF) {
+		return Result{Name: name, Err: unwrapPathError(err)}
+	}
+	head, _ := reader.Peek(reader.Buffered())
+	if bytes.IndexByte(head, 0) >= 0 {
+		return Result{Name: name, Binary: true}
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	summary, err := PatternString(scanner, name, s.options, s.matcher, events)
+	// ошибку вывода Run узнает из relay, это не ошибка файла
+	if events.caused(err) {
+		err = nil
+	}
+
+	return Result{Name: name, Summary: summary, Err: err}
+}
+
+// unwrapPathError - без "open имя:", имя файла и так есть в сообщении
+func unwrapPathError(err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,138 @@
+package search
+
+import (
+	"L2_12/internal/cli"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Matcher - все шаблоны (-e, -f) собраны в одно регулярное выражение RE2
+type Matcher struct {
+	re *regexp.Regexp
+	// word - -w: совпадение должно быть отдельным словом, сам шаблон в группе 1
+	word bool
+	// whole - -w: шаблон целиком, им проверяем более короткие совпадения с того же места
+	whole *regexp.Regexp
+}
+
+// NewMatcher - собираем шаблоны с учетом флагов -F, -i, -w, -x.
+// Шаблон с переводами строк, как в GNU grep, делится на несколько шаблонов
+func NewMatcher(patterns []string, options *cli.Options) (*Matcher, error) {
+	var alternatives []string
+	for _, pattern := range patterns {
+		for _, p := range strings.Split(pattern, "\n") {
+			if options.Fixed {
+				p = regexp.QuoteMeta(p)
+			} else if _, err := regexp.Compile(p); err != nil {
+				// проверяем каждый шаблон отдельно, чтобы ошибка указывала на конкретный
+				return nil, fmt.Errorf("неверный шаблон %q: %w", p, err)
+			}
+			alternatives = append(alternatives, "(?:"+p+")")
+		}
+	}
+
+	// без шаблонов (пустой файл -f) не совпадает ни одна строка
+	expr := `[^\x00-\x{10FFFF}]`
+	if len(alternatives) > 0 {
+		expr = strings.Join(alternatives, "|")
+	}
+
+	word := false
+	whole := `^(?:` + expr + `)$`
+	switch {
+	case options.Line:
+		expr = `^(?:` + expr + `)$`
+	case options.Word:
+		// граница слова перед шаблоном проверяется регуляркой, после - в boundaryAfter:
+		// \b в RE2 понимает только ASCII, а слова бывают и кириллические
+		expr = `(?:^|[^\pL\p{Nd}_])(` + expr + `)`
+		word = true
+	}
+
+	if options.Ignore {
+		expr, whole = `(?i)`+expr, `(?i)`+whole
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("неверный шаблон: %w", err)
+	}
+	// как в GNU grep: из совпадений в одной позиции берется самое длинное (важно для -o)
+	re.Longest()
+
+	m := &Matcher{re: re, word: word}
+	if word {
+		if m.whole, err = regexp.Compile(whole); err != nil {
+			return nil, fmt.Errorf("неверный шаблон: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// Match - строка подходит под один из шаблонов
+func (m *Matcher) Match(line string) bool {
+	if !m.word {
+		return m.re.MatchString(line)
+	}
+
+	return len(m.Find(line)) > 0
+}
+
+// Find - границы всех совпадений в строке [начало, конец), включая пустые
+func (m *Matcher) Find(line string) []Span {
+	var spans []Span
+
+	if !m.word {
+		for _, loc := range m.re.FindAllStringIndex(line, -1) {
+			spans = append(spans, Span{Start: loc[0], End: loc[1]})
+		}
+
+		return spans
+	}
+
+	for _, loc := range m.re.FindAllStringSubmatchIndex(line, -1) {
+		start, end := loc[2], loc[3]
+		if end, ok := m.wordEnd(line, start, end); ok {
+			spans = append(spans, Span{Start: start, End: end})
+		}
+	}
+
+	return spans
+}
+
+// wordEnd - конец самого длинного совпадения с позиции start, после которого кончается слово.
+// Как GNU grep, если самое длинное упирается в букву, пробуем совпадения короче:
+// -w 'a|a-b' в строке "a-bc" находит "a"
+func (m *Matcher) wordEnd(line string, start, end int) (int, bool) {
+	if boundaryAfter(line, end) {
+		return end, true
+	}
+
+	for end--; end > start; end-- {
+		if boundaryAfter(line, end) && m.whole.MatchString(line[start:end]) {
+			return end, true
+		}
+	}
+
+	return 0, false
+}
+
+// boundaryAfter - после позиции pos конец строки или символ, который не входит в слово
+func boundaryAfter(line string, pos int) bool {
+	if pos >= len(line) {
+		return true
+	}
+
+	r, _ := utf8.DecodeRuneInString(line[pos:])
+
+	return !isWordRune(r)
+}
+
+// isWordRune - символы слова для -w: буквы, цифры и подчеркивание
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -4,88 +4,166 @@ import (
 	"L2_12/internal/cli"
 	"bufio"
 	"fmt"
-	"strings"
 )
 
-// PatternString - поиск строк по шаблону
-func PatternString(scanner *bufio.Scanner, options *cli.Options, searchedStr string) {
-	lineNum := 0                                      // номер строки
-	afterCount := 0                                   // количество строк после шаблона для(-A)
-	beforeBuffer := make([]string, 0, options.Before) // строки до шаблона для(-B)
-	matches := 0                                      // количество найденных совпадений
-	printedMatches := make(map[string]bool)           // чтобы не дублировать совпадения
+// contextLine - строка, отложенная для вывода перед совпадением (-B)
+type contextLine struct {
+	num     int
+	text    string
+	matches []Span
+}
+
+// Summary - итог поиска в одном источнике
+type Summary struct {
+	// Matches - количество подходящих строк (не больше -m)
+	Matches int
+	// Grouped - выведена хотя бы одна группа строк, нужно для "--" между файлами
+	Grouped bool
+}
+
+// emitter - превращает строки в события и следит за группами контекста
+type emitter struct {
+	renderer Renderer
+	name     string
+	options  *cli.Options
+	// lastEmitted - номер последней отданной строки, 0 - еще ничего не отдавали
+	lastEmitted int
+	err         error
+}
+
+// PatternString - поиск строк по шаблонам в одном источнике, name - его имя в событиях.
+// Результат уходит в renderer событиями: строки, разделители групп, счетчик или имя файла
+func PatternString(scanner *bufio.Scanner, name string, options *cli.Options, matcher *Matcher, renderer Renderer) (Summary, error) {
+	e := &emitter{renderer: renderer, name: name, options: options}
+
+	// при -c, -l и -L строки только считаем, а для -l и -L хватает первого совпадения
+	countOnly := options.Count || options.FilesWith || options.FilesWithout
+	maxCount := options.MaxCount
+	if options.FilesWith || options.FilesWithout {
+		maxCount = 1
+	}
+
+	lineNum := 0                                           // номер строки
+	afterCount := 0                                        // сколько строк после совпадения еще выводить (-A)
+	beforeBuffer := make([]contextLine, 0, options.Before) // строки до совпадения (-B)
+	matches := 0                                           // количество подходящих строк
+
+	// после -m совпадений дочитываем только контекст -A, как GNU grep
+	limitReached := func() bool { return maxCount >= 0 && matches >= maxCount }
 
-	for scanner.Scan() {
+	for e.err == nil && (!limitReached() || afterCount > 0 && !countOnly) && scanner.Scan() {
 		text := scanner.Text()
 		lineNum++
 
-		// // проверка для флага -i
-		checkText := text
-		checkStr := searchedStr
-		if options.Ignore {
-			checkText = strings.ToLower(checkText)
-			checkStr = strings.ToLower(checkStr)
+		// при -c совпадения в строке не нужны, достаточно проверки
+		var spans []Span
+		var found bool
+		if countOnly {
+			found = matcher.Match(text)
+		} else {
+			spans, found = nonEmpty(matcher.Find(text))
 		}
 
-		// проверка для флага -F
-		matched := false
-		if options.Fixed {
-			matched = strings.Contains(checkText, checkStr)
-		} else {
-			matched = checkText == checkStr
+		if limitReached() {
+			e.line(KindContext, lineNum, text, spans)
+			afterCount--
+			continue
 		}
 
 		// проверка для флага -v
-		if options.Invert {
-			matched = !matched
-		}
+		matched := found != options.Invert
 
-		// // проверка для флага -c
 		if matched {
 			matches++
 		}
-		if options.Count {
+		if countOnly {
 			continue
 		}
 
 		switch {
 		case matched:
-			// выводим буфер перед совпадением (-B)
-			for _, b := range beforeBuffer {
-				fmt.Println(b)
+			// буфер всегда примыкает к совпадению, поэтому по его началу видно, есть ли разрыв
+			first := lineNum - len(beforeBuffer)
+			if hasContext(options) && e.lastEmitted > 0 && first > e.lastEmitted+1 {
+				e.emit(Event{Kind: KindSeparator, File: name})
 			}
 
-			// выводим совпадение, если его еще не выводили
-			if !printedMatches[text] {
-				printedMatches[text] = true
-				if options.LineNum {
-					fmt.Printf("%d:%s\n", lineNum, text)
-				} else {
-					fmt.Println(text)
-				}
+			for _, b := range beforeBuffer {
+				e.line(KindContext, b.num, b.text, b.matches)
 			}
+			beforeBuffer = beforeBuffer[:0]
 
+			// при -v у подходящей строки совпадений нет
+			if options.Invert {
+				spans = nil
+			}
+			e.line(KindMatch, lineNum, text, spans)
 			afterCount = options.After
-			beforeBuffer = beforeBuffer[:0]
 
-			// активируем -A
+		// активируем -A
 		case afterCount > 0:
-			fmt.Println(text)
+			e.line(KindContext, lineNum, text, spans)
 			afterCount--
 
-			// тут активируем -B
-		default:
-			if options.Before > 0 {
-				if len(beforeBuffer) == options.Before {
-					beforeBuffer = beforeBuffer[1:]
-				}
-				beforeBuffer = append(beforeBuffer, text)
+		// тут активируем -B
+		case options.Before > 0:
+			if len(beforeBuffer) == options.Before {
+				beforeBuffer = append(beforeBuffer[:0], beforeBuffer[1:]...)
 			}
+			beforeBuffer = append(beforeBuffer, contextLine{num: lineNum, text: text, matches: spans})
 		}
 	}
 
-	// считаем количество строк для шаблона -n
-	if options.Count {
-		fmt.Println(matches)
+	summary := Summary{Matches: matches, Grouped: e.lastEmitted > 0}
+
+	if e.err != nil {
+		return summary, e.err
+	}
+	if err := scanner.Err(); err != nil {
+		return summary, fmt.Errorf("ошибка чтения: %w", err)
+	}
+
+	switch {
+	case options.FilesWith && matches > 0, options.FilesWithout && matches == 0:
+		e.emit(Event{Kind: KindFile, File: name})
+	case options.Count && !options.FilesWith && !options.FilesWithout:
+		e.emit(Event{Kind: KindCount, File: name, Count: matches})
+	}
+
+	return summary, e.err
+}
+
+func (e *emitter) line(kind Kind, num int, text string, spans []Span) {
+	e.lastEmitted = num
+	e.emit(Event{Kind: kind, File: e.name, Line: num, Text: text, Matches: spans})
+}
+
+// emit - после первой ошибки рендерера события больше не отправляются
+func (e *emitter) emit(event Event) {
+	if e.err == nil {
+		e.err = e.renderer.Render(event)
 	}
 }
+
+// nonEmpty - отбрасываем пустые совпадения, found - было хотя бы одно совпадение, пусть и пустое
+func nonEmpty(spans []Span) ([]Span, bool) {
+	found := len(spans) > 0
+
+	n := 0
+	for _, span := range spans {
+		if span.Start < span.End {
+			spans[n] = span
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, found
+	}
+
+	return spans[:n], found
+}
+
+// hasContext - заданы -A, -B или -C, группы строк разделяются "--"
+func hasContext(options *cli.Options) bool {
+	return options.After > 0 || options.Before > 0
+}
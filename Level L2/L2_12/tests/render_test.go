@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"L2_12/internal/cli"
+	"L2_12/internal/render"
+	"L2_12/internal/search"
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestRenderJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		event search.Event
+		want  string
+	}{
+		{
+			name:  "match with spans",
+			event: search.Event{Kind: search.KindMatch, File: "a.txt", Line: 3, Text: "foo bar foo", Matches: []search.Span{{Start: 0, End: 3}, {Start: 8, End: 11}}},
+			want: `{"type":"match","file":"a.txt","line":3,"text":"foo bar foo",` +
+				`"matches":[{"start":0,"end":3,"text":"foo"},{"start":8,"end":11,"text":"foo"}]}` + "\n",
+		},
+		{
+			// смещения в байтах, а не в символах
+			name:  "match with multibyte text",
+			event: search.Event{Kind: search.KindMatch, File: "a.txt", Line: 1, Text: "ёж <b>", Matches: []search.Span{{Start: 0, End: 4}}},
+			want:  `{"type":"match","file":"a.txt","line":1,"text":"ёж <b>","matches":[{"start":0,"end":4,"text":"ёж"}]}` + "\n",
+		},
+		{
+			name:  "context without matches",
+			event: search.Event{Kind: search.KindContext, File: "-", Line: 2, Text: "x"},
+			want:  `{"type":"context","file":"-","line":2,"text":"x","matches":[]}` + "\n",
+		},
+		{
+			name:  "separator is not printed",
+			event: search.Event{Kind: search.KindSeparator, File: "a.txt"},
+			want:  "",
+		},
+		{
+			name:  "count",
+			event: search.Event{Kind: search.KindCount, File: "a.txt", Count: 2},
+			want:  `{"type":"count","file":"a.txt","count":2}` + "\n",
+		},
+		{
+			name:  "file",
+			event: search.Event{Kind: search.KindFile, File: "dir/a.txt"},
+			want:  `{"type":"file","file":"dir/a.txt"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			renderer := render.NewJSON(&out)
+			if err := renderer.Render(tt.event); err != nil {
+				t.Fatal(err)
+			}
+			if err := renderer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := out.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJSONOutput - поиск с контекстом целиком: группы не разделяются, смежность видна по номерам строк
+func TestJSONOutput(t *testing.T) {
+	got := grepTo(t, cli.Options{Patterns: []string{"foo"}, Before: 1}, func(w io.Writer, _ cli.Options) search.Renderer {
+		return render.NewJSON(w)
+	}, "ctx.txt")
+
+	want := `{"type":"context","file":"ctx.txt","line":1,"text":"a","matches":[]}` + "\n" +
+		`{"type":"match","file":"ctx.txt","line":2,"text":"foo","matches":[{"start":0,"end":3,"text":"foo"}]}` + "\n" +
+		`{"type":"context","file":"ctx.txt","line":5,"text":"d","matches":[]}` + "\n" +
+		`{"type":"match","file":"ctx.txt","line":6,"text":"foo","matches":[{"start":0,"end":3,"text":"foo"}]}` + "\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// sgr - текст в цветовых последовательностях GNU grep
+func sgr(color, text string) string {
+	return "\x1b[" + color + "m\x1b[K" + text + "\x1b[m\x1b[K"
+}
+
+func TestColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cli.Options
+		paths   []string
+		want    string
+	}{
+		{
+			name:    "match only",
+			options: cli.Options{Patterns: []string{"o"}},
+			paths:   []string{"adj.txt"},
+			want:    "f" + sgr("01;31", "o") + sgr("01;31", "o") + "\n" + "f" + sgr("01;31", "o") + sgr("01;31", "o") + "\n",
+		},
+		{
+			name:    "filename, line number and context",
+			options: cli.Options{Patterns: []string{"foo"}, Before: 1, LineNum: true, WithFilename: true},
+			paths:   []string{"ctx.txt"},
+			want: sgr("35", "ctx.txt") + sgr("36", "-") + sgr("32", "1") + sgr("36", "-") + "a\n" +
+				sgr("35", "ctx.txt") + sgr("36", ":") + sgr("32", "2") + sgr("36", ":") + sgr("01;31", "foo") + "\n" +
+				sgr("36", "--") + "\n" +
+				sgr("35", "ctx.txt") + sgr("36", "-") + sgr("32", "5") + sgr("36", "-") + "d\n" +
+				sgr("35", "ctx.txt") + sgr("36", ":") + sgr("32", "6") + sgr("36", ":") + sgr("01;31", "foo") + "\n",
+		},
+		{
+			name:    "only matching",
+			options: cli.Options{Patterns: []string{"ba."}, OnlyMatching: true, LineNum: true},
+			paths:   []string{"max.txt"},
+			want:    sgr("32", "3") + sgr("36", ":") + sgr("01;31", "bar") + "\n" + sgr("32", "5") + sgr("36", ":") + sgr("01;31", "baz") + "\n",
+		},
+		{
+			name:    "count",
+			options: cli.Options{Patterns: []string{"foo"}, Count: true},
+			paths:   []string{"ctx.txt", "max.txt"},
+			want:    sgr("35", "ctx.txt") + sgr("36", ":") + "2\n" + sgr("35", "max.txt") + sgr("36", ":") + "3\n",
+		},
+		{
+			name:    "files with matches",
+			options: cli.Options{Patterns: []string{"bar"}, FilesWith: true},
+			paths:   []string{"ctx.txt", "max.txt", "words.txt"},
+			want:    sgr("35", "max.txt") + "\n" + sgr("35", "words.txt") + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := grepTo(t, tt.options, func(w io.Writer, options cli.Options) search.Renderer {
+				return render.NewText(w, render.TextOptions{
+					LineNum:      options.LineNum,
+					WithFilename: options.WithFilename,
+					OnlyMatching: options.OnlyMatching,
+					Color:        true,
+				})
+			}, tt.paths...)
+
+			if got != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", got, tt.want)
+			}
+		})
+	}
+}
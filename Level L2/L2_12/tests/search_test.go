@@ -0,0 +1,288 @@
+package tests
+
+import (
+	"L2_12/internal/cli"
+	"L2_12/internal/render"
+	"L2_12/internal/search"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// files - тестовые файлы, ожидания ниже сверены с GNU grep 3.8
+var files = map[string]string{
+	"ctx.txt":   "a\nfoo\nb\nc\nd\nfoo\n",
+	"adj.txt":   "foo\nx\nfoo\ny\n",
+	"max.txt":   "foo\nfoo\nbar\nfoo\nbaz\n",
+	"words.txt": "foo_bar foo\nxfoo foox\nЁж ёжик\na-bc\n",
+	// каталог для -r, --include и --exclude
+	"dir/a.go":     "package foo\n",
+	"dir/b.txt":    "foo\nbar\n",
+	"dir/sub/c.go": "// foo\n",
+	"dir/sub/d.md": "nothing\n",
+}
+
+// grep - поиск в файлах из files с опциями как после разбора флагов в main, текстовый вывод
+func grep(t *testing.T, options cli.Options, paths ...string) string {
+	t.Helper()
+
+	return grepTo(t, options, func(w io.Writer, options cli.Options) search.Renderer {
+		return render.NewText(w, render.TextOptions{
+			LineNum:      options.LineNum,
+			WithFilename: options.WithFilename,
+			OnlyMatching: options.OnlyMatching,
+		})
+	}, paths...)
+}
+
+// grepTo - поиск в файлах из files с выводом через renderer
+func grepTo(t *testing.T, options cli.Options, renderer func(io.Writer, cli.Options) search.Renderer, paths ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+
+	if options.MaxCount == 0 {
+		options.MaxCount = -1
+	}
+	options.WithFilename = options.WithFilename || len(paths) > 1
+
+	matcher, err := search.NewMatcher(options.Patterns, &options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	if _, failed := search.NewSearcher(&options, matcher).Run(paths, renderer(&out, options), &stderr); failed {
+		t.Fatalf("grep failed: %s", stderr.String())
+	}
+
+	return out.String()
+}
+
+func TestContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cli.Options
+		paths   []string
+		want    string
+	}{
+		{
+			name:    "separator between groups",
+			options: cli.Options{Patterns: []string{"foo"}, Before: 1, After: 1},
+			paths:   []string{"ctx.txt"},
+			want:    "a\nfoo\nb\n--\nd\nfoo\n",
+		},
+		{
+			name:    "adjacent groups merge",
+			options: cli.Options{Patterns: []string{"foo"}, After: 1, LineNum: true},
+			paths:   []string{"adj.txt"},
+			want:    "1:foo\n2-x\n3:foo\n4-y\n",
+		},
+		{
+			name:    "separator between files",
+			options: cli.Options{Patterns: []string{"foo"}, Before: 1, LineNum: true},
+			paths:   []string{"ctx.txt", "adj.txt"},
+			want: "ctx.txt-1-a\nctx.txt:2:foo\n--\nctx.txt-5-d\nctx.txt:6:foo\n--\n" +
+				"adj.txt:1:foo\nadj.txt-2-x\nadj.txt:3:foo\n",
+		},
+		{
+			name:    "trailing context then next file",
+			options: cli.Options{Patterns: []string{"foo"}, After: 1},
+			paths:   []string{"adj.txt", "ctx.txt"},
+			want:    "adj.txt:foo\nadj.txt-x\nadj.txt:foo\nadj.txt-y\n--\nctx.txt:foo\nctx.txt-b\n--\nctx.txt:foo\n",
+		},
+		{
+			name:    "no separator without context",
+			options: cli.Options{Patterns: []string{"foo"}},
+			paths:   []string{"ctx.txt", "adj.txt"},
+			want:    "ctx.txt:foo\nctx.txt:foo\nadj.txt:foo\nadj.txt:foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grep(t, tt.options, tt.paths...); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cli.Options
+		want    string
+	}{
+		{
+			// после -m строки дочитываются только как контекст -A, даже подходящие
+			name:    "-m with -A",
+			options: cli.Options{Patterns: []string{"foo"}, MaxCount: 1, After: 2, LineNum: true},
+			want:    "1:foo\n2-foo\n3-bar\n",
+		},
+		{
+			name:    "-m 2 with -A",
+			options: cli.Options{Patterns: []string{"foo"}, MaxCount: 2, After: 1},
+			want:    "foo\nfoo\nbar\n",
+		},
+		{
+			name:    "-c counts up to -m",
+			options: cli.Options{Patterns: []string{"foo"}, MaxCount: 2, Count: true},
+			want:    "2\n",
+		},
+		{
+			name:    "-v with -m",
+			options: cli.Options{Patterns: []string{"foo"}, MaxCount: 2, Invert: true, LineNum: true},
+			want:    "3:bar\n5:baz\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grep(t, tt.options, "max.txt"); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWordBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cli.Options
+		want    string
+	}{
+		{
+			// _ - часть слова, а foo в foox и xfoo не отдельное слово
+			name:    "only whole words",
+			options: cli.Options{Patterns: []string{"foo"}, Word: true, LineNum: true},
+			want:    "1:foo_bar foo\n",
+		},
+		{
+			name:    "-o prints the word match",
+			options: cli.Options{Patterns: []string{"foo"}, Word: true, OnlyMatching: true},
+			want:    "foo\n",
+		},
+		{
+			// самое длинное совпадение "a-b" упирается в букву, подходит более короткое "a"
+			name:    "shorter alternative",
+			options: cli.Options{Patterns: []string{"a|a-b"}, Word: true, OnlyMatching: true},
+			want:    "a\n",
+		},
+		{
+			// в UTF-8 локали кириллица - буквы, поэтому "ёж" в "ёжик" не слово
+			name:    "unicode letters",
+			options: cli.Options{Patterns: []string{"ёж"}, Word: true, Ignore: true, OnlyMatching: true},
+			want:    "Ёж\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grep(t, tt.options, "words.txt"); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		options cli.Options
+		paths   []string
+		want    string
+	}{
+		{
+			name:    "several files with line numbers",
+			options: cli.Options{Patterns: []string{"ba"}, LineNum: true},
+			paths:   []string{"max.txt", "ctx.txt", "dir/b.txt"},
+			want:    "max.txt:3:bar\nmax.txt:5:baz\ndir/b.txt:2:bar\n",
+		},
+		{
+			name:    "-l in order of arguments",
+			options: cli.Options{Patterns: []string{"bar"}, FilesWith: true},
+			paths:   []string{"words.txt", "ctx.txt", "max.txt"},
+			want:    "words.txt\nmax.txt\n",
+		},
+		{
+			name:    "-L",
+			options: cli.Options{Patterns: []string{"bar"}, FilesWithout: true},
+			paths:   []string{"words.txt", "ctx.txt", "max.txt", "adj.txt"},
+			want:    "ctx.txt\nadj.txt\n",
+		},
+		{
+			name:    "-r keeps the path as typed",
+			options: cli.Options{Patterns: []string{"foo"}, Recursive: true, WithFilename: true},
+			paths:   []string{"./dir"},
+			want:    "./dir/a.go:package foo\n./dir/b.txt:foo\n./dir/sub/c.go:// foo\n",
+		},
+		{
+			name:    "-r drops trailing slashes",
+			options: cli.Options{Patterns: []string{"foo"}, Recursive: true, FilesWith: true},
+			paths:   []string{"dir//"},
+			want:    "dir/a.go\ndir/b.txt\ndir/sub/c.go\n",
+		},
+		{
+			name:    "-r keeps dots inside the path",
+			options: cli.Options{Patterns: []string{"foo"}, Recursive: true, FilesWith: true},
+			paths:   []string{"dir/./sub"},
+			want:    "dir/./sub/c.go\n",
+		},
+		{
+			name:    "-r without paths searches the current directory without ./",
+			options: cli.Options{Patterns: []string{"foo"}, Recursive: true, FilesWith: true, Include: []string{"*.go"}},
+			want:    "dir/a.go\ndir/sub/c.go\n",
+		},
+		{
+			name:    "-L with -r",
+			options: cli.Options{Patterns: []string{"foo"}, Recursive: true, FilesWithout: true},
+			paths:   []string{"./dir"},
+			want:    "./dir/sub/d.md\n",
+		},
+		{
+			name:    "--include",
+			options: cli.Options{Patterns: []string{"foo"}, Recursive: true, FilesWith: true, Include: []string{"*.go"}},
+			paths:   []string{"dir"},
+			want:    "dir/a.go\ndir/sub/c.go\n",
+		},
+		{
+			name:    "--exclude",
+			options: cli.Options{Patterns: []string{"o"}, Recursive: true, FilesWith: true, Exclude: []string{"*.go"}},
+			paths:   []string{"dir"},
+			want:    "dir/b.txt\ndir/sub/d.md\n",
+		},
+		{
+			// --exclude важнее --include
+			name:    "--include and --exclude",
+			options: cli.Options{Patterns: []string{"o"}, Recursive: true, FilesWith: true, Include: []string{"*.go", "*.md"}, Exclude: []string{"c.*"}},
+			paths:   []string{"dir"},
+			want:    "dir/a.go\ndir/sub/d.md\n",
+		},
+		{
+			name:    "--include applies to file arguments",
+			options: cli.Options{Patterns: []string{"foo"}, Include: []string{"*.go"}},
+			paths:   []string{"dir/a.go", "dir/b.txt", "dir/sub/c.go"},
+			want:    "dir/a.go:package foo\ndir/sub/c.go:// foo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grep(t, tt.options, tt.paths...); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,29 +3,64 @@ package main
 import (
 	"L2_13/internal/analoguecut"
 	"L2_13/internal/cli"
-	"bufio"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
 )
 
 func main() {
-	flags, fileName := cli.ParseFlags()
-
-	var scanner *bufio.Scanner
-	if fileName == "" || fileName == "-" {
-		// Если файла нет или указан "-", читаем из stdin
-		scanner = bufio.NewScanner(os.Stdin)
-	} else {
-		// Иначе открываем файл
-		file, err := os.Open(fileName)
-		if err != nil {
-			log.Fatal("Ошибка открытия файла:", err)
+	os.Exit(run())
+}
+
+func run() int {
+	flags, files, err := cli.ParseFlags()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cut:", err)
+		return 1
+	}
+
+	// Если файлов нет, читаем из stdin
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	cutter := analoguecut.New(os.Stdout, flags)
+
+	// ошибка в одном файле не мешает обработать остальные, но код выхода будет 1
+	code := 0
+	for _, name := range files {
+		if err = cutFile(cutter, name); err != nil {
+			cutter.Flush()
+			fmt.Fprintf(os.Stderr, "cut: %s: %v\n", name, err)
+			code = 1
+		}
+	}
+
+	if err = cutter.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, "cut:", err)
+		return 1
+	}
+
+	return code
+}
+
+// cutFile - "-" - это stdin, как в GNU cut
+func cutFile(cutter *analoguecut.Cutter, name string) error {
+	if name == "-" {
+		return cutter.Cut(os.Stdin)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		// без "open имя:", имя файла и так есть в сообщении
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return pathErr.Err
 		}
-		defer file.Close()
-		scanner = bufio.NewScanner(file)
+		return err
 	}
+	defer file.Close()
 
-	str := analoguecut.Cut(scanner, flags)
-	fmt.Println(str)
+	return cutter.Cut(file)
 }
@@ -3,38 +3,357 @@ package analoguecut
 import (
 	"L2_13/internal/cli"
 	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"strings"
+	"unicode/utf8"
 )
 
-// Cut - утилита cut
-func Cut(scanner *bufio.Scanner, flags cli.Flags) string {
-	// проверяем что в файле остались строки
-	if !scanner.Scan() {
-		return ""
+// Cutter - cut для нескольких источников подряд с общим выводом.
+// Данные читаются потоково: в памяти только текущая строка или запись CSV
+type Cutter struct {
+	flags cli.Flags
+	out   *bufio.Writer
+	// headerDone - заголовок уже выведен: при --header он печатается один раз, из первого файла
+	headerDone bool
+	// names - имена выводимых колонок из заголовка первого файла в порядке вывода.
+	// В следующих файлах колонки ищутся по этим именам, чтобы порядок везде совпадал с заголовком
+	names []string
+}
+
+// New - конструктор, вывод буферизуется до Flush
+func New(w io.Writer, flags cli.Flags) *Cutter {
+	return &Cutter{flags: flags, out: bufio.NewWriter(w)}
+}
+
+// Cut - утилита cut для одного источника: обрабатываем все строки r и пишем результат в w
+func Cut(r io.Reader, w io.Writer, flags cli.Flags) error {
+	c := New(w, flags)
+	if err := c.Cut(r); err != nil {
+		c.Flush()
+		return err
+	}
+
+	return c.Flush()
+}
+
+// Flush - сбрасываем буфер вывода
+func (c *Cutter) Flush() error {
+	return c.out.Flush()
+}
+
+// Cut - обрабатываем очередной источник. При -z строки разделены и заканчиваются NUL,
+// иначе \n (\r не отрезается, как в GNU cut)
+func (c *Cutter) Cut(r io.Reader) error {
+	if c.flags.CSV {
+		return c.cutCSV(r)
+	}
+
+	terminator := byte('\n')
+	if c.flags.ZeroTerminated {
+		terminator = 0
+	}
+
+	flags := c.flags
+	header := flags.Header
+	reader := bufio.NewReader(r)
+	var columns []int
+
+	for {
+		line, err := reader.ReadString(terminator)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if line == "" {
+			return nil
+		}
+		line = strings.TrimSuffix(line, string(terminator))
+
+		// по заголовку узнаем номера колонок, выводим его только для первого файла
+		if header {
+			header = false
+			if columns, err = c.columns(strings.Split(line, flags.Delimiter)); err != nil {
+				return err
+			}
+			if c.headerDone {
+				continue
+			}
+			c.headerDone = true
+		}
+
+		result, ok := "", false
+		if flags.Header {
+			result, ok = pickLine(line, columns, flags)
+		} else {
+			result, ok = Line(line, flags)
+		}
+		if ok {
+			c.out.WriteString(result)
+			if err := c.out.WriteByte(terminator); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// cutCSV - --csv: записи RFC 4180 (поля в кавычках, с разделителями и переводами строк внутри),
+// выбранные поля выводятся тоже в CSV и при необходимости берутся в кавычки
+func (c *Cutter) cutCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.Comma, _ = utf8.DecodeRuneInString(c.flags.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	writer := csv.NewWriter(c.out)
+	writer.Comma, _ = utf8.DecodeRuneInString(c.flags.OutputDelimiter)
+
+	header := c.flags.Header
+	var columns []int
+	var selected []string
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			writer.Flush()
+			return err
+		}
+
+		if header {
+			header = false
+			if columns, err = c.columns(record); err != nil {
+				return err
+			}
+			if c.headerDone {
+				continue
+			}
+			c.headerDone = true
+		}
+
+		// запись из одного поля - это строка без разделителя: выводим как есть, при -s пропускаем
+		if len(record) == 1 && c.flags.Separator {
+			continue
+		}
+		switch {
+		case len(record) == 1:
+			selected = append(selected[:0], record[0])
+		case c.flags.Header:
+			selected = pick(selected[:0], record, columns)
+		default:
+			selected = selectFields(selected[:0], record, c.flags.Ranges)
+		}
+
+		// csv.Writer пишет запись из одного пустого поля как пустую строку, которую
+		// читатель CSV пропустит, поэтому такое поле берем в кавычки сами
+		if len(selected) == 1 && selected[0] == "" {
+			writer.Flush()
+			c.out.WriteString(`""` + "\n")
+			continue
+		}
+
+		if err = writer.Write(selected); err != nil {
+			return err
+		}
 	}
 
-	line := scanner.Text()
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// columns - номера выводимых колонок (с 0) по заголовку очередного файла в порядке вывода.
+// В первом файле это колонки из -f в порядке заголовка, в остальных - колонки с теми же
+// именами в том же порядке, даже если в заголовке они стоят иначе
+func (c *Cutter) columns(header []string) ([]int, error) {
+	if c.names == nil {
+		ranges, err := resolve(header, c.flags)
+		if err != nil {
+			return nil, err
+		}
+
+		columns := []int{}
+		c.names = []string{}
+		for _, r := range ranges {
+			for i := r.Start - 1; i < min(r.End, len(header)); i++ {
+				columns = append(columns, i)
+				c.names = append(c.names, header[i])
+			}
+		}
+		return columns, nil
+	}
 
-	// Если задан -s пропускаем строки без разделителя
-	if flags.Separator && !strings.Contains(line, flags.Delimiter) {
-		return ""
+	// колонки с одинаковыми именами сопоставляем по порядку: вторая со второй и т.д.
+	columns := make([]int, 0, len(c.names))
+	seen := make(map[string]int)
+	for _, name := range c.names {
+		i := nth(header, name, seen[name])
+		if i < 0 {
+			return nil, fmt.Errorf("колонка %q не найдена в заголовке", name)
+		}
+		seen[name]++
+		columns = append(columns, i)
 	}
 
-	// делим строки по разделителю
-	splitLines := strings.Split(line, flags.Delimiter)
-	var result []string
+	return columns, nil
+}
 
-	// берем нужные слова
-	for _, field := range flags.Fields {
-		if field > 0 && field-1 < len(splitLines) {
-			result = append(result, splitLines[field-1])
+// nth - номер n-й (с 0) колонки с именем name, -1 - такой нет
+func nth(header []string, name string, n int) int {
+	for i, column := range header {
+		if column != name {
+			continue
+		}
+		if n == 0 {
+			return i
 		}
+		n--
 	}
 
-	// проверяем что список не пустой
-	if len(result) == 0 {
-		return ""
+	return -1
+}
+
+// resolve - номера колонок заголовка с именами из -f (все колонки с таким именем), с --complement
+func resolve(header []string, flags cli.Flags) ([]cli.Range, error) {
+	var ranges []cli.Range
+
+	for _, name := range flags.Names {
+		found := false
+		for i, column := range header {
+			if column == name {
+				ranges = append(ranges, cli.Range{Start: i + 1, End: i + 1})
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("колонка %q не найдена в заголовке", name)
+		}
+	}
+
+	ranges = cli.Merge(ranges)
+	if flags.Complement {
+		ranges = cli.Complement(ranges)
+	}
+
+	return ranges, nil
+}
+
+// selectFields - поля записи из диапазонов, в порядке записи и по одному разу
+func selectFields(dst, record []string, ranges []cli.Range) []string {
+	for _, r := range ranges {
+		if r.Start > len(record) {
+			break
+		}
+		dst = append(dst, record[r.Start-1:min(r.End, len(record))]...)
+	}
+
+	return dst
+}
+
+// pick - поля записи по номерам колонок в их порядке, колонок за концом записи нет
+func pick(dst, record []string, columns []int) []string {
+	for _, i := range columns {
+		if i < len(record) {
+			dst = append(dst, record[i])
+		}
+	}
+
+	return dst
+}
+
+// pickLine - -f с --header: поля строки по номерам колонок через выходной разделитель
+func pickLine(line string, columns []int, flags cli.Flags) (string, bool) {
+	// строки без разделителя выводятся целиком, а при -s пропускаются
+	if !strings.Contains(line, flags.Delimiter) {
+		return line, !flags.Separator
+	}
+
+	return strings.Join(pick(nil, strings.Split(line, flags.Delimiter), columns), flags.OutputDelimiter), true
+}
+
+// Line - выбранная часть одной строки; false - строку не выводим (-s без разделителя)
+func Line(line string, flags cli.Flags) (string, bool) {
+	switch flags.Mode {
+	case cli.ModeBytes:
+		return positions(line, len(line), flags, func(i int) int { return i }), true
+	case cli.ModeChars:
+		return chars(line, flags), true
+	default:
+		return fields(line, flags)
+	}
+}
+
+// fields - -f: поля выводятся в порядке строки и по одному разу, через выходной разделитель
+func fields(line string, flags cli.Flags) (string, bool) {
+	// строки без разделителя выводятся целиком, а при -s пропускаются
+	if !strings.Contains(line, flags.Delimiter) {
+		return line, !flags.Separator
+	}
+
+	var sb strings.Builder
+	ranges := flags.Ranges
+	printed := false
+
+	for n := 1; ; n++ {
+		field, rest, more := strings.Cut(line, flags.Delimiter)
+
+		// пропускаем диапазоны, которые закончились до поля n
+		for len(ranges) > 0 && ranges[0].End < n {
+			ranges = ranges[1:]
+		}
+		if len(ranges) == 0 {
+			break
+		}
+
+		if ranges[0].Start <= n {
+			if printed {
+				sb.WriteString(flags.OutputDelimiter)
+			}
+			sb.WriteString(field)
+			printed = true
+		}
+
+		if !more {
+			break
+		}
+		line = rest
+	}
+
+	return sb.String(), true
+}
+
+// chars - -c: позиции считаются в символах UTF-8, а не в байтах
+func chars(line string, flags cli.Flags) string {
+	// offsets[i] - байтовое смещение i-го символа, последний элемент - длина строки
+	offsets := make([]int, 0, len(line)+1)
+	for i := range line {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(line))
+
+	return positions(line, utf8.RuneCountInString(line), flags, func(i int) int { return offsets[i] })
+}
+
+// positions - -b и -c: count позиций в строке, offset переводит номер позиции (с 0) в байты.
+// --output-delimiter выводится между диапазонами, но не внутри них
+func positions(line string, count int, flags cli.Flags, offset func(int) int) string {
+	var sb strings.Builder
+	printed := false
+
+	for _, r := range flags.Ranges {
+		if r.Start > count {
+			break
+		}
+
+		if printed {
+			sb.WriteString(flags.OutputDelimiter)
+		}
+		sb.WriteString(line[offset(r.Start-1):offset(min(r.End, count))])
+		printed = true
 	}
 
-	return strings.Join(result, flags.Delimiter)
+	return sb.String()
 }
@@ -1,75 +1,352 @@
 package cli
 
 import (
+	"errors"
 	"flag"
-	"log"
+	"fmt"
+	"math"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// boolFlags и valueFlags - однобуквенные флаги без значения и со значением
+const (
+	boolFlags  = "sz"
+	valueFlags = "bcfd"
+)
+
+// longValueFlags - длинные флаги со значением, которое может идти отдельным аргументом
+var longValueFlags = []string{"output-delimiter"}
+
+// Mode - что вырезаем из строки
+type Mode int
+
+const (
+	ModeFields Mode = iota // -f: поля между разделителями
+	ModeBytes              // -b: байты
+	ModeChars              // -c: символы (руны UTF-8)
+)
+
+// Range - диапазон номеров с 1, включительно. End = math.MaxInt - до конца строки
+type Range struct {
+	Start int
+	End   int
+}
+
 // Flags - структура хранящая флаги
 type Flags struct {
-	Fields    []int
-	Delimiter string
-	Separator bool
+	Mode Mode
+	// Ranges - отсортированы, пересекающиеся слиты, --complement уже применен
+	Ranges          []Range
+	Delimiter       string // -d
+	OutputDelimiter string // --output-delimiter, для -f по умолчанию равен -d
+	Separator       bool   // -s
+	Complement      bool   // --complement
+	ZeroTerminated  bool   // -z: строки заканчиваются NUL, а не \n
+	CSV             bool   // --csv: записи RFC 4180, поля в кавычках могут содержать разделитель
+	// Header - --header: первая строка файла - заголовок, -f задает имена колонок (Names),
+	// колонки ищутся по заголовку каждого файла и выводятся в порядке заголовка первого
+	Header bool
+	Names  []string
 }
 
-// ParseFlags - парсим флаги
-func ParseFlags() (Flags, string) {
-	var delimiter, fields string
-	var separated bool
-	var parsedFields []int
+// ParseFlags - парсим флаги, возвращаем их и файлы (пустой список - stdin)
+func ParseFlags() (Flags, []string, error) {
+	var delimiter, bytesList, charsList, fieldsList, outputDelimiter string
+	var separated, complement, zero, csvMode, header bool
 
-	flag.StringVar(&fields, "f", "", "Номера полей которые нужно вывести")
+	flag.StringVar(&bytesList, "b", "", "Номера байтов которые нужно вывести")
+	flag.StringVar(&charsList, "c", "", "Номера символов которые нужно вывести")
+	flag.StringVar(&fieldsList, "f", "", "Номера полей которые нужно вывести")
 	flag.StringVar(&delimiter, "d", "\t", "Разделитель")
 	flag.BoolVar(&separated, "s", false, "Показывать только строки содержащие разделитель")
+	flag.BoolVar(&complement, "complement", false, "Выводить все, кроме выбранного")
+	flag.StringVar(&outputDelimiter, "output-delimiter", "", "Разделитель в выводе")
+	flag.BoolVar(&zero, "z", false, "Строки разделены NUL, а не переводом строки")
+	flag.BoolVar(&csvMode, "csv", false, "Разбирать строки как CSV (RFC 4180), разделитель по умолчанию - запятая")
+	flag.BoolVar(&header, "header", false, "Первая строка - заголовок, -f задает имена колонок")
 
-	flag.Parse()
-	args := flag.Args()
-	if len(args) < 1 {
-		log.Fatal("нужно передать имя файла")
-	}
-	fileName := args[0]
+	// ошибка разбора обрабатывается самим flag.CommandLine (ExitOnError)
+	_ = flag.CommandLine.Parse(expandShortFlags(os.Args[1:]))
 
-	parsedFields = ParseFields(fields)
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
 
 	flags := Flags{
-		Delimiter: delimiter,
-		Fields:    parsedFields,
-		Separator: separated,
+		Delimiter:      delimiter,
+		Separator:      separated,
+		Complement:     complement,
+		ZeroTerminated: zero,
+		CSV:            csvMode,
+		Header:         header,
+	}
+
+	// в CSV по умолчанию запятая, а не табуляция
+	if csvMode && !set["d"] {
+		delimiter = ","
+		flags.Delimiter = delimiter
+	}
+
+	// должен быть задан ровно один список
+	var list string
+	lists := 0
+	for _, l := range []struct {
+		name string
+		mode Mode
+		list string
+	}{{"b", ModeBytes, bytesList}, {"c", ModeChars, charsList}, {"f", ModeFields, fieldsList}} {
+		if set[l.name] {
+			flags.Mode, list = l.mode, l.list
+			lists++
+		}
+	}
+	switch {
+	case lists == 0:
+		return Flags{}, nil, errors.New("нужно указать список байтов, символов или полей (-b, -c или -f)")
+	case lists > 1:
+		return Flags{}, nil, errors.New("можно указать только один список")
+	}
+
+	if flags.Mode != ModeFields && set["d"] {
+		return Flags{}, nil, errors.New("разделитель -d можно указать только вместе с -f")
+	}
+	if flags.Mode != ModeFields && separated {
+		return Flags{}, nil, errors.New("флаг -s имеет смысл только вместе с -f")
+	}
+	if flags.Mode != ModeFields && (csvMode || header) {
+		return Flags{}, nil, errors.New("--csv и --header работают только вместе с -f")
+	}
+	if csvMode && zero {
+		return Flags{}, nil, errors.New("--csv нельзя совмещать с -z")
+	}
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return Flags{}, nil, fmt.Errorf("разделитель должен быть одним символом: %q", delimiter)
 	}
 
-	return flags, fileName
+	if header {
+		names, err := ParseNames(list)
+		if err != nil {
+			return Flags{}, nil, err
+		}
+		flags.Names = names
+	} else {
+		ranges, err := ParseFields(list)
+		if err != nil {
+			return Flags{}, nil, err
+		}
+		if complement {
+			ranges = Complement(ranges)
+		}
+		flags.Ranges = ranges
+	}
+
+	// по умолчанию поля выводятся через входной разделитель, а байты и символы - без него
+	switch {
+	case set["output-delimiter"]:
+		flags.OutputDelimiter = outputDelimiter
+	case flags.Mode == ModeFields:
+		flags.OutputDelimiter = delimiter
+	}
+	if csvMode && utf8.RuneCountInString(flags.OutputDelimiter) != 1 {
+		return Flags{}, nil, errors.New("в режиме --csv разделитель вывода должен быть одним символом")
+	}
+
+	return flags, flag.Args(), nil
+}
+
+// ParseNames - имена колонок для --header через запятую
+func ParseNames(s string) ([]string, error) {
+	names := strings.Split(s, ",")
+	for _, name := range names {
+		if name == "" {
+			return nil, fmt.Errorf("пустое имя колонки в списке %q", s)
+		}
+	}
+
+	return names, nil
 }
 
-// ParseFields - парсим , и  -
-func ParseFields(s string) []int {
-	var result []int
+// ParseFields - парсим список вида 1,3-5,7-,-2 (через запятую или пробел), как в GNU cut.
+// Возвращаем диапазоны по возрастанию, пересекающиеся слиты в один
+func ParseFields(s string) ([]Range, error) {
+	var ranges []Range
+
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(parts) == 0 || strings.HasPrefix(s, ",") || strings.HasSuffix(s, ",") || strings.Contains(s, ",,") {
+		return nil, errors.New("нумерация полей и позиций начинается с 1")
+	}
 
-	for _, part := range strings.Split(s, ",") {
-		part = strings.TrimSpace(part)
-		if part == "" {
+	for _, part := range parts {
+		start, end, isRange := strings.Cut(part, "-")
+
+		if !isRange {
+			n, err := parseNumber(part)
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, Range{Start: n, End: n})
 			continue
 		}
 
-		if strings.Contains(part, "-") {
-			// Диапазон
-			bounds := strings.SplitN(part, "-", 2)
-			start, err1 := strconv.Atoi(strings.TrimSpace(bounds[0]))
-			end, err2 := strconv.Atoi(strings.TrimSpace(bounds[1]))
-			if err1 != nil || err2 != nil || start > end {
-				continue // некорректный диапазон игнорируем
+		if start == "" && end == "" {
+			return nil, errors.New("диапазон без границ: -")
+		}
+
+		r := Range{Start: 1, End: math.MaxInt}
+		var err error
+		if start != "" {
+			if r.Start, err = parseNumber(start); err != nil {
+				return nil, err
 			}
-			for i := start; i <= end; i++ {
-				result = append(result, i)
+		}
+		if end != "" {
+			if r.End, err = parseNumber(end); err != nil {
+				return nil, err
 			}
-		} else {
-			// Одиночное число
-			if n, err := strconv.Atoi(part); err == nil {
-				result = append(result, n)
+		}
+		if r.Start > r.End {
+			return nil, fmt.Errorf("убывающий диапазон: %s", part)
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return Merge(ranges), nil
+}
+
+// Complement - все номера, которые не входят в ranges
+func Complement(ranges []Range) []Range {
+	var result []Range
+
+	next := 1
+	for _, r := range ranges {
+		if r.Start > next {
+			result = append(result, Range{Start: next, End: r.Start - 1})
+		}
+		if r.End == math.MaxInt {
+			return result
+		}
+		next = r.End + 1
+	}
+
+	return append(result, Range{Start: next, End: math.MaxInt})
+}
+
+// Merge - сортируем и сливаем пересекающиеся диапазоны. Соседние (1,2) остаются отдельными:
+// GNU cut выводит между ними --output-delimiter
+func Merge(ranges []Range) []Range {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(ranges, func(a, b Range) int {
+		if a.Start != b.Start {
+			return a.Start - b.Start
+		}
+		return b.End - a.End
+	})
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+func parseNumber(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || strings.HasPrefix(s, "+") {
+		return 0, fmt.Errorf("неверный номер поля или позиции: %q", s)
+	}
+	if n < 1 {
+		return 0, errors.New("нумерация полей и позиций начинается с 1")
+	}
+
+	return n, nil
+}
+
+// expandShortFlags - приводим GNU запись к виду, который понимает пакет flag:
+// -d, -f2 превращаем в -d , -f 2, -sz в -s -z. Как и GNU cut, флаги можно
+// писать после файла: они переносятся вперед, остальное идет после --
+func expandShortFlags(args []string) []string {
+	expanded := make([]string, 0, len(args))
+	var positional []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		// после -- флагов нет
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			positional = append(positional, arg)
+			continue
+		}
+
+		if arg[1] == '-' && slices.Contains(longValueFlags, arg[2:]) && i+1 < len(args) {
+			expanded = append(expanded, arg, args[i+1])
+			i++
+			continue
+		}
+
+		if len(arg) == 2 || arg[1] == '-' {
+			expanded = append(expanded, arg)
+			// значение отдельного флага (-d -) может начинаться с минуса, его не разбираем
+			if len(arg) == 2 && strings.IndexByte(valueFlags, arg[1]) >= 0 && i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
 			}
+			continue
+		}
+
+		name := arg[1:]
+		if !knownShortFlags(name) {
+			// неизвестная буква - отдаем как есть, flag сообщит об ошибке
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		for j := 0; j < len(name); j++ {
+			c := name[j]
+			expanded = append(expanded, "-"+string(c))
+
+			if strings.IndexByte(valueFlags, c) < 0 {
+				continue
+			}
+			// остаток аргумента - значение флага, а если его нет - следующий аргумент
+			if j+1 < len(name) {
+				expanded = append(expanded, name[j+1:])
+			} else if i+1 < len(args) {
+				i++
+				expanded = append(expanded, args[i])
+			}
+			break
+		}
+	}
+
+	return append(append(expanded, "--"), positional...)
+}
+
+// knownShortFlags - все буквы до первого флага со значением известны
+func knownShortFlags(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if strings.IndexByte(valueFlags, name[i]) >= 0 {
+			return true
+		}
+		if strings.IndexByte(boolFlags, name[i]) < 0 {
+			return false
 		}
 	}
 
-	return result
+	return true
 }
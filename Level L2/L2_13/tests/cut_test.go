@@ -0,0 +1,292 @@
+package tests
+
+import (
+	"L2_13/internal/analoguecut"
+	"L2_13/internal/cli"
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// input - строки с разделителем, без него и короткая; ожидания ниже сверены с GNU cut 9.1
+const input = "a:b:c:d:e\nnodelim\nx:y\n"
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []cli.Range
+		wantErr bool
+	}{
+		{list: "1,3-5,7-", want: []cli.Range{r(1, 1), r(3, 5), r(7, math.MaxInt)}},
+		{list: "-2", want: []cli.Range{r(1, 2)}},
+		{list: "1 2", want: []cli.Range{r(1, 1), r(2, 2)}},
+		// пересекающиеся сливаются, соседние остаются отдельными
+		{list: "3-4,1-3,1", want: []cli.Range{r(1, 4)}},
+		{list: "5,1-2,3", want: []cli.Range{r(1, 2), r(3, 3), r(5, 5)}},
+		{list: "", wantErr: true},
+		{list: "0", wantErr: true},
+		{list: "0-2", wantErr: true},
+		{list: "1,,2", wantErr: true},
+		{list: ",1", wantErr: true},
+		{list: "1,", wantErr: true},
+		{list: "3-1", wantErr: true},
+		{list: "-", wantErr: true},
+		{list: "a", wantErr: true},
+		{list: "+1", wantErr: true},
+		{list: "1-2-3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			got, err := cli.ParseFields(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func r(start, end int) cli.Range {
+	return cli.Range{Start: start, End: end}
+}
+
+// cut - результат cut для input с флагами как после разбора в ParseFlags
+func cut(t *testing.T, input string, flags cli.Flags, list string) string {
+	t.Helper()
+
+	ranges, err := cli.ParseFields(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags.Complement {
+		ranges = cli.Complement(ranges)
+	}
+	flags.Ranges = ranges
+
+	if flags.Delimiter == "" {
+		flags.Delimiter = ":"
+	}
+	if flags.OutputDelimiter == "" && flags.Mode == cli.ModeFields {
+		flags.OutputDelimiter = flags.Delimiter
+	}
+
+	var out bytes.Buffer
+	if err = analoguecut.Cut(strings.NewReader(input), &out, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.String()
+}
+
+func TestCut(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags cli.Flags
+		list  string
+		want  string
+	}{
+		{name: "open end", list: "3-", want: "c:d:e\nnodelim\n\n"},
+		{name: "open start", list: "-2", want: "a:b\nnodelim\nx:y\n"},
+		{name: "field and open range", list: "2,4-", want: "b:d:e\nnodelim\ny\n"},
+		{name: "overlapping ranges", list: "1-2,2-3", want: "a:b:c\nnodelim\nx:y\n"},
+		{name: "complement", flags: cli.Flags{Complement: true}, list: "2", want: "a:c:d:e\nnodelim\nx\n"},
+		{name: "complement of ranges", flags: cli.Flags{Complement: true}, list: "2-3,5", want: "a:d\nnodelim\nx\n"},
+		{name: "-s skips lines without delimiter", flags: cli.Flags{Separator: true}, list: "2", want: "b\ny\n"},
+		{name: "output delimiter", flags: cli.Flags{OutputDelimiter: "|"}, list: "1,3", want: "a|c\nnodelim\nx\n"},
+		{name: "chars open end", flags: cli.Flags{Mode: cli.ModeChars}, list: "2-", want: ":b:c:d:e\nodelim\n:y\n"},
+		{name: "chars list", flags: cli.Flags{Mode: cli.ModeChars}, list: "-2,4", want: "a::\nnoe\nx:\n"},
+		{name: "chars complement", flags: cli.Flags{Mode: cli.ModeChars, Complement: true}, list: "2-3", want: "a:c:d:e\nnelim\nx\n"},
+		{
+			// --output-delimiter между диапазонами, но не внутри них
+			name:  "bytes output delimiter",
+			flags: cli.Flags{Mode: cli.ModeBytes, OutputDelimiter: ","},
+			list:  "1,3-4",
+			want:  "a,b:\nn,de\nx,y\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cut(t, input, tt.flags, tt.list); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCutChars - -c считает символы UTF-8, а GNU cut режет байты посреди символа
+func TestCutChars(t *testing.T) {
+	got := cut(t, "привет\n", cli.Flags{Mode: cli.ModeChars}, "2-3")
+	if got != "ри\n" {
+		t.Errorf("got %q, want %q", got, "ри\n")
+	}
+}
+
+// cutFiles - один Cutter на несколько файлов подряд, как при запуске с несколькими путями
+func cutFiles(t *testing.T, flags cli.Flags, files ...string) (string, error) {
+	t.Helper()
+
+	if flags.Delimiter == "" {
+		flags.Delimiter = ","
+	}
+	if flags.OutputDelimiter == "" {
+		flags.OutputDelimiter = flags.Delimiter
+	}
+
+	var out bytes.Buffer
+	cutter := analoguecut.New(&out, flags)
+	for _, file := range files {
+		if err := cutter.Cut(strings.NewReader(file)); err != nil {
+			cutter.Flush()
+			return out.String(), err
+		}
+	}
+
+	err := cutter.Flush()
+
+	return out.String(), err
+}
+
+func TestCutCSVAndHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   cli.Flags
+		list    string
+		files   []string
+		want    string
+		wantErr string
+	}{
+		{
+			// разделитель и перевод строки внутри кавычек - часть поля, на выводе поле снова в кавычках
+			name:  "csv quoted fields",
+			flags: cli.Flags{CSV: true},
+			list:  "2-3",
+			files: []string{"a,\"b,1\",c\n\"x\"\"y\",2,\"multi\nline\"\n"},
+			want:  "\"b,1\",c\n2,\"multi\nline\"\n",
+		},
+		{
+			name:  "csv quote inside field",
+			flags: cli.Flags{CSV: true},
+			list:  "1",
+			files: []string{"\"x\"\"y\",2\n"},
+			want:  "\"x\"\"y\"\n",
+		},
+		{
+			name:  "csv record without delimiter",
+			flags: cli.Flags{CSV: true},
+			list:  "2",
+			files: []string{"nodelim\na,b\n"},
+			want:  "nodelim\nb\n",
+		},
+		{
+			name:  "csv -s skips record without delimiter",
+			flags: cli.Flags{CSV: true, Separator: true},
+			list:  "2",
+			files: []string{"nodelim\na,b\n"},
+			want:  "b\n",
+		},
+		{
+			name:  "csv empty field stays quoted",
+			flags: cli.Flags{CSV: true},
+			list:  "2",
+			files: []string{"a,,c\n"},
+			want:  "\"\"\n",
+		},
+		{
+			name:  "csv output delimiter",
+			flags: cli.Flags{CSV: true, OutputDelimiter: ";"},
+			list:  "1,3",
+			files: []string{"a;1,b,c\n"},
+			want:  "\"a;1\";c\n",
+		},
+		{
+			// колонки выводятся в порядке заголовка первого файла, а не в порядке -f
+			name:  "header selects columns by name",
+			flags: cli.Flags{Header: true, Names: []string{"age", "name"}},
+			files: []string{"id,name,age\n1,ann,30\n"},
+			want:  "name,age\nann,30\n",
+		},
+		{
+			name:  "header complement",
+			flags: cli.Flags{Header: true, Complement: true, Names: []string{"id"}},
+			files: []string{"id,name,age\n1,ann,30\n"},
+			want:  "name,age\nann,30\n",
+		},
+		{
+			name:  "header keeps column order across files",
+			flags: cli.Flags{Header: true, Names: []string{"name", "age"}},
+			files: []string{"id,name,age\n1,ann,30\n", "age,id,name\n40,2,bob\n", "name,age\ncid,50\n"},
+			want:  "name,age\nann,30\nbob,40\ncid,50\n",
+		},
+		{
+			name:  "header with other delimiter across files",
+			flags: cli.Flags{Header: true, Delimiter: ":", Names: []string{"name", "age"}},
+			files: []string{"id:name:age\n1:ann:30\n", "age:id:name\n40:2:bob\n"},
+			want:  "name:age\nann:30\nbob:40\n",
+		},
+		{
+			// одноименные колонки сопоставляются по порядку: первая с первой, вторая со второй
+			name:  "header duplicate names",
+			flags: cli.Flags{Header: true, Names: []string{"a"}},
+			files: []string{"a,b,a\n1,2,3\n", "b,a,a\n5,6,7\n"},
+			want:  "a,a\n1,3\n6,7\n",
+		},
+		{
+			name:  "csv header keeps column order across files",
+			flags: cli.Flags{CSV: true, Header: true, Names: []string{"name", "age"}},
+			files: []string{"id,name,age\n1,\"Doe, J\",30\n", "age,name\n40,\"bob \"\"b\"\"\"\n"},
+			want:  "name,age\n\"Doe, J\",30\n\"bob \"\"b\"\"\",40\n",
+		},
+		{
+			name:    "header column not found",
+			flags:   cli.Flags{Header: true, Names: []string{"email"}},
+			files:   []string{"id,name\n1,ann\n"},
+			wantErr: `колонка "email" не найдена в заголовке`,
+		},
+		{
+			// колонка есть в первом файле, но пропала во втором: то, что успели вывести, остается
+			name:    "header column missing in next file",
+			flags:   cli.Flags{Header: true, Names: []string{"name", "age"}},
+			files:   []string{"id,name,age\n1,ann,30\n", "id,name\n2,bob\n"},
+			want:    "name,age\nann,30\n",
+			wantErr: `колонка "age" не найдена в заголовке`,
+		},
+		{
+			name:    "csv header column missing in next file",
+			flags:   cli.Flags{CSV: true, Header: true, Names: []string{"age"}},
+			files:   []string{"age\n30\n", "name\nbob\n"},
+			want:    "age\n30\n",
+			wantErr: `колонка "age" не найдена в заголовке`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := tt.flags
+			if tt.list != "" {
+				ranges, err := cli.ParseFields(tt.list)
+				if err != nil {
+					t.Fatal(err)
+				}
+				flags.Ranges = ranges
+			}
+
+			got, err := cutFiles(t, flags, tt.files...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
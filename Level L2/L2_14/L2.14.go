@@ -2,27 +2,30 @@ package main
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
-func or(channels ...<-chan interface{}) <-chan interface{} {
-	chDone := make(chan interface{})
+// or - канал закрывается, как только любой из каналов прислал значение или закрылся.
+// После этого горутины на остальных каналах выходят по chDone и не висят до конца программы
+func or[T any](channels ...<-chan T) <-chan T {
+	chDone := make(chan T)
+	if len(channels) == 0 {
+		return chDone
+	}
 
-	// запускаем отдельную горутину
-	go func() {
-		// проходимся по каналам
-		for _, channel := range channels {
-			go func() {
-				// ждем пока в канал не отправится значение или закроется
-				<-channel
-				select {
-				// отправляем значение
-				case chDone <- struct{}{}:
-				default:
-				}
-			}()
-		}
-	}()
+	var once sync.Once
+	for _, channel := range channels {
+		go func() {
+			// ждем значения или закрытия канала, либо сигнала от другой горутины
+			select {
+			case <-channel:
+				// закрываем ровно один раз, сигнал не теряется, даже если его никто не ждет
+				once.Do(func() { close(chDone) })
+			case <-chDone:
+			}
+		}()
+	}
 
 	return chDone
 }
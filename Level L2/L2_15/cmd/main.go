@@ -1,35 +1,31 @@
 package main
 
 import (
-	"L2_15/internal/myminishell/commands"
+	"L2_15/internal/myminishell/shell"
 	"L2_15/internal/reader"
 	"fmt"
+	"os"
 )
 
 func main() {
+	sh := shell.New(os.Stdin, os.Stdout, os.Stderr)
+	in := reader.New(os.Stdin)
+
 	for {
-		args, eof := reader.Read()
+		// о завершившихся фоновых заданиях сообщаем перед чтением строки, как bash
+		sh.ReportJobs()
 
+		line, eof := in.Read()
 		if eof {
 			fmt.Println("\nexit")
 			break
 		}
-		if args == nil {
-			continue
-		}
 
-		if len(args) > 1 {
-			// если несколько команд — это пайплайн
-			err := commands.RunPipeline(args)
-			if err != nil {
-				fmt.Println("Ошибка в пайплайне:", err)
-			}
-		} else {
-			// иначе просто выполняем команду
-			err := commands.CallCommands(args)
-			if err != nil {
-				fmt.Println("Команда не распознана корректно: ", err)
-			}
+		sh.Run(line)
+		if sh.Exited() {
+			break
 		}
 	}
+
+	os.Exit(sh.Status())
 }
@@ -1,5 +1,3 @@
 module L2_15
 
 go 1.24.5
-
-require github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
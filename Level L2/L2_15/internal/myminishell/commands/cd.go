@@ -3,19 +3,35 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
 // ChangeDir - сменить папку
-func ChangeDir(args []string) error {
+func ChangeDir(w io.Writer, args []string) error {
 	fullPath := strings.Join(args[1:], " ")
 
 	err := os.Chdir(fullPath)
 	if err != nil {
 		return errors.New("Error changing directory: " + err.Error())
 	}
-	fmt.Printf("Changing dir: %v\n", fullPath)
+	fmt.Fprintf(w, "Changing dir: %v\n", fullPath)
+
+	return nil
+}
+
+// CheckDir - проверить, что в папку можно перейти, не переходя в нее
+func CheckDir(args []string) error {
+	fullPath := strings.Join(args[1:], " ")
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return errors.New("Error changing directory: " + err.Error())
+	}
+	if !info.IsDir() {
+		return errors.New("Error changing directory: " + fullPath + ": not a directory")
+	}
 
 	return nil
 }
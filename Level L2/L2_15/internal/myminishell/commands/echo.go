@@ -2,15 +2,16 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"strings"
 )
 
 // Echo - вывод аргументов
-func Echo(args []string) {
+func Echo(w io.Writer, args []string) {
 	if len(args) < 2 {
-		fmt.Println()
+		fmt.Fprintln(w)
 		return
 	}
 
-	fmt.Println(strings.Join(args[1:], " "))
+	fmt.Fprintln(w, strings.Join(args[1:], " "))
 }
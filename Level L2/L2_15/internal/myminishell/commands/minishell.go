@@ -2,82 +2,99 @@ package commands
 
 import (
 	"fmt"
+	"io"
 )
 
-// CallCommands - обрабатываем команды
-func CallCommands(pipeline [][]string) error {
-	if len(pipeline) == 0 {
-		return nil
-	}
+// Env - окружение встроенной команды. В конвейере потоки - это концы пайпов или файлы перенаправлений
+type Env struct {
+	In       io.Reader
+	Out, Err io.Writer
+	// Subshell - команда запущена в конвейере или в фоне. Как и в bash, такая команда
+	// не меняет состояние самого шелла: cd в "cd /tmp | cat" папку не меняет
+	Subshell bool
+}
 
-	// если пайп лайн из нескольких команд — запускаем RunPipeline
-	if len(pipeline) > 1 {
-		return RunPipeline(pipeline)
-	}
+// Builtin - встроенная команда. Возвращает код выхода, как у внешней
+type Builtin func(env Env, args []string) int
 
-	// иначе просто выполняем одну команду
-	args := pipeline[0]
-	if len(args) == 0 {
-		return nil
-	}
+// builtins - встроенные команды, которым не нужно состояние шелла
+var builtins = map[string]Builtin{
+	"cd":   runCd,
+	"pwd":  runPwd,
+	"echo": runEcho,
+	"kill": runKill,
+	"ps":   runPs,
+}
 
-	switch args[0] {
-	case "cd":
-		return runCd(args)
-	case "pwd":
-		return runPwd()
-	case "echo":
-		return runEcho(args)
-	case "kill":
-		return runKill(args)
-	case "ps":
-		return runPs(args)
-	default:
-		return runExternal(args) // поддержка внешних команд
-	}
+// Lookup - встроенная команда по имени
+func Lookup(name string) (Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+// fail - печатаем ошибку встроенной команды и возвращаем код 1
+func fail(env Env, name string, err error) int {
+	fmt.Fprintf(env.Err, "%s: %v\n", name, err)
+	return 1
 }
 
 // runCd - запустить cd
-func runCd(args []string) error {
+func runCd(env Env, args []string) int {
 	if len(args) < 2 {
-		return nil
+		return 0
+	}
+
+	if env.Subshell {
+		// папку не меняем, но ошибку о несуществующей папке показываем
+		if err := CheckDir(args); err != nil {
+			return fail(env, args[0], err)
+		}
+		return 0
 	}
 
-	return ChangeDir(args)
+	if err := ChangeDir(env.Out, args); err != nil {
+		return fail(env, args[0], err)
+	}
+
+	return 0
 }
 
 // runPwd - запустить pwd
-func runPwd() error {
+func runPwd(env Env, args []string) int {
 	dir, err := Pwd()
 	if err != nil {
-		return err
+		return fail(env, args[0], err)
 	}
-	println(dir)
+	fmt.Fprintln(env.Out, dir)
 
-	return nil
+	return 0
 }
 
 // runEcho - запустить echo
-func runEcho(args []string) error {
-	Echo(args)
+func runEcho(env Env, args []string) int {
+	Echo(env.Out, args)
 
-	return nil
+	return 0
 }
 
 // runKill - убить процесс
-func runKill(args []string) error {
+func runKill(env Env, args []string) int {
 	if len(args) < 2 {
-		return nil
+		return 0
 	}
 	if err := KillProcess(args[1]); err != nil {
-		return err
+		return fail(env, args[0], err)
 	}
-	fmt.Printf("Процесс %v успешно завершен\n", args[1])
+	fmt.Fprintf(env.Out, "Процесс %v успешно завершен\n", args[1])
 
-	return nil
+	return 0
 }
 
 // runPs - запустить ps
-func runPs(args []string) error {
-	return Ps(args)
+func runPs(env Env, args []string) int {
+	if err := Ps(env.Out, env.Err, args); err != nil {
+		return fail(env, args[0], err)
+	}
+
+	return 0
 }
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// List - вся строка: and-or списки, разделенные ; или &
+type List struct {
+	Items []Item
+}
+
+// Item - and-or список и то, как его запускать
+type Item struct {
+	AndOr      *AndOr
+	Background bool // список завершался &
+}
+
+// AndOr - конвейеры, связанные && и ||. Ops[i] (And или Or) стоит между Pipelines[i] и Pipelines[i+1]
+type AndOr struct {
+	Pipelines []*Pipeline
+	Ops       []TokenKind
+}
+
+// Pipeline - команды, связанные |
+type Pipeline struct {
+	Commands []*Command
+}
+
+// Command - простая команда: аргументы и перенаправления в порядке записи
+type Command struct {
+	Args      []string
+	Redirects []Redirect
+}
+
+// Redirect - перенаправление дескриптора Fd. Target - имя файла, а для <& и >& номер дескриптора
+type Redirect struct {
+	Fd     int
+	Op     TokenKind // Less, Great, DGreat, LessAnd или GreatAnd
+	Target string
+}
+
+// String - and-or список в виде, пригодном для повторного разбора, для вывода jobs
+func (a *AndOr) String() string {
+	var b strings.Builder
+	for i, p := range a.Pipelines {
+		if i > 0 {
+			if a.Ops[i-1] == And {
+				b.WriteString(" && ")
+			} else {
+				b.WriteString(" || ")
+			}
+		}
+		b.WriteString(p.String())
+	}
+	return b.String()
+}
+
+// String - конвейер одной строкой
+func (p *Pipeline) String() string {
+	commands := make([]string, len(p.Commands))
+	for i, c := range p.Commands {
+		commands[i] = c.String()
+	}
+	return strings.Join(commands, " | ")
+}
+
+// String - команда одной строкой, слова с пробелами и спецсимволами в кавычках
+func (c *Command) String() string {
+	words := make([]string, 0, len(c.Args)+len(c.Redirects))
+	for _, arg := range c.Args {
+		words = append(words, quote(arg))
+	}
+	for _, r := range c.Redirects {
+		words = append(words, r.String())
+	}
+	return strings.Join(words, " ")
+}
+
+// String - перенаправление, номер дескриптора пишется, только если он не по умолчанию
+func (r Redirect) String() string {
+	op := map[TokenKind]string{Less: "<", Great: ">", DGreat: ">>", LessAnd: "<&", GreatAnd: ">&"}[r.Op]
+
+	prefix := ""
+	if r.Fd != DefaultFd(r.Op) {
+		prefix = strconv.Itoa(r.Fd)
+	}
+
+	target := r.Target
+	if r.Op != LessAnd && r.Op != GreatAnd {
+		target = quote(target)
+	}
+
+	return prefix + op + target
+}
+
+// DefaultFd - дескриптор, который перенаправляет оператор без номера: stdin для < и <&, иначе stdout
+func DefaultFd(op TokenKind) int {
+	if op == Less || op == LessAnd {
+		return 0
+	}
+	return 1
+}
+
+// quote - слово в одинарных кавычках, если без них оно разобралось бы иначе
+func quote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\n'\"\\|&;<>#$`*?()") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind - вид токена
+type TokenKind int
+
+const (
+	Word      TokenKind = iota // слово, уже без кавычек и экранирования
+	IONumber                   // номер дескриптора вплотную к редиректу: 2 в 2>file
+	Pipe                       // |
+	And                        // &&
+	Or                         // ||
+	Semicolon                  // ;
+	Amp                        // &
+	Less                       // <
+	Great                      // >
+	DGreat                     // >>
+	LessAnd                    // <&
+	GreatAnd                   // >&
+)
+
+// Token - токен строки
+type Token struct {
+	Kind TokenKind
+	Text string
+}
+
+// operators - операторы, длинные раньше коротких, чтобы && не разобрался как два &
+var operators = []Token{
+	{And, "&&"},
+	{Or, "||"},
+	{DGreat, ">>"},
+	{LessAnd, "<&"},
+	{GreatAnd, ">&"},
+	{Pipe, "|"},
+	{Amp, "&"},
+	{Semicolon, ";"},
+	{Less, "<"},
+	{Great, ">"},
+}
+
+// Tokenize - разбиваем строку на слова и операторы. Кавычки работают как в sh:
+// в '...' все буквально, в "..." экранируются только \ " $ и `, вне кавычек \ экранирует любой символ.
+// Оператор внутри кавычек - часть слова, а # в начале слова - комментарий до конца строки
+func Tokenize(line string) ([]Token, error) {
+	var tokens []Token
+	var word strings.Builder
+	// inWord - слово начато, даже если оно пустое, как в ""
+	// quoted - в слове были кавычки или \, такое слово не номер дескриптора
+	inWord, quoted := false, false
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, Token{Kind: Word, Text: word.String()})
+		}
+		word.Reset()
+		inWord, quoted = false, false
+	}
+
+	for i := 0; i < len(line); {
+		c := line[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+			i++
+
+		case c == '#' && !inWord:
+			i = len(line)
+
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("незакрытая кавычка %c", c)
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			inWord, quoted = true, true
+			i += end + 2
+
+		case c == '"':
+			n, err := doubleQuoted(line[i+1:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord, quoted = true, true
+			i += n + 2
+
+		case c == '\\':
+			// \ в конце строки просто отбрасываем
+			if i+1 < len(line) {
+				word.WriteByte(line[i+1])
+			}
+			inWord, quoted = true, true
+			i += 2
+
+		case strings.IndexByte("|&;<>", c) >= 0:
+			op := operator(line[i:])
+			if (c == '<' || c == '>') && inWord && !quoted && isNumber(word.String()) {
+				tokens = append(tokens, Token{Kind: IONumber, Text: word.String()})
+				word.Reset()
+				inWord = false
+			} else {
+				flush()
+			}
+			tokens = append(tokens, op)
+			i += len(op.Text)
+
+		default:
+			word.WriteByte(c)
+			inWord = true
+			i++
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// doubleQuoted - содержимое "..." до закрывающей кавычки. Возвращает длину содержимого в строке
+func doubleQuoted(s string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return i, nil
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\\"$`", s[i+1]) >= 0:
+			word.WriteByte(s[i+1])
+			i++
+		default:
+			word.WriteByte(c)
+		}
+	}
+
+	return 0, fmt.Errorf("незакрытая кавычка %c", '"')
+}
+
+// operator - самый длинный оператор в начале s
+func operator(s string) Token {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op.Text) {
+			return op
+		}
+	}
+	panic("parser: нет оператора в " + s)
+}
+
+// isNumber - непустая строка из одних цифр
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
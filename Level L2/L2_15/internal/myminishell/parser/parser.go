@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// errUnexpectedEnd - строка кончилась посреди конструкции, например после | или &&
+var errUnexpectedEnd = errors.New("синтаксическая ошибка: неожиданный конец строки")
+
+// Parse - разбор строки в AST по грамматике
+//
+//	list     := and_or ((';' | '&') and_or)* [';' | '&']
+//	and_or   := pipeline (('&&' | '||') pipeline)*
+//	pipeline := command ('|' command)*
+//	command  := (word | redirect)+
+//	redirect := [io_number] ('<' | '>' | '>>' | '<&' | '>&') word
+//
+// Пустая строка или строка из одного комментария дает пустой List
+func Parse(line string) (*List, error) {
+	tokens, err := Tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	return p.list()
+}
+
+// parser - рекурсивный спуск по токенам
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+// peek - текущий токен, false - токены кончились
+func (p *parser) peek() (Token, bool) {
+	if p.pos >= len(p.tokens) {
+		return Token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept - забираем текущий токен, если он одного из видов kinds
+func (p *parser) accept(kinds ...TokenKind) (Token, bool) {
+	tok, ok := p.peek()
+	if !ok {
+		return Token{}, false
+	}
+	for _, kind := range kinds {
+		if tok.Kind == kind {
+			p.pos++
+			return tok, true
+		}
+	}
+	return Token{}, false
+}
+
+func (p *parser) list() (*List, error) {
+	list := &List{}
+
+	for p.pos < len(p.tokens) {
+		andOr, err := p.andOr()
+		if err != nil {
+			return nil, err
+		}
+
+		item := Item{AndOr: andOr}
+		if tok, ok := p.accept(Semicolon, Amp); ok {
+			item.Background = tok.Kind == Amp
+		} else if tok, ok := p.peek(); ok {
+			return nil, unexpected(tok)
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	return list, nil
+}
+
+func (p *parser) andOr() (*AndOr, error) {
+	pipeline, err := p.pipeline()
+	if err != nil {
+		return nil, err
+	}
+	andOr := &AndOr{Pipelines: []*Pipeline{pipeline}}
+
+	for {
+		tok, ok := p.accept(And, Or)
+		if !ok {
+			return andOr, nil
+		}
+		if pipeline, err = p.pipeline(); err != nil {
+			return nil, err
+		}
+		andOr.Ops = append(andOr.Ops, tok.Kind)
+		andOr.Pipelines = append(andOr.Pipelines, pipeline)
+	}
+}
+
+func (p *parser) pipeline() (*Pipeline, error) {
+	pipeline := &Pipeline{}
+
+	for {
+		command, err := p.command()
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Commands = append(pipeline.Commands, command)
+
+		if _, ok := p.accept(Pipe); !ok {
+			return pipeline, nil
+		}
+	}
+}
+
+func (p *parser) command() (*Command, error) {
+	command := &Command{}
+
+	for {
+		tok, ok := p.peek()
+		if !ok {
+			break
+		}
+
+		if tok.Kind == Word {
+			command.Args = append(command.Args, tok.Text)
+			p.pos++
+			continue
+		}
+
+		redirect, ok, err := p.redirect()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		command.Redirects = append(command.Redirects, redirect)
+	}
+
+	// команда из одних перенаправлений допустима: "> file" создает пустой файл
+	if len(command.Args) == 0 && len(command.Redirects) == 0 {
+		if tok, ok := p.peek(); ok {
+			return nil, unexpected(tok)
+		}
+		return nil, errUnexpectedEnd
+	}
+
+	return command, nil
+}
+
+// redirect - перенаправление с необязательным номером дескриптора. false - на месте не перенаправление
+func (p *parser) redirect() (Redirect, bool, error) {
+	start := p.pos
+
+	fd := -1
+	if tok, ok := p.accept(IONumber); ok {
+		n, err := strconv.Atoi(tok.Text)
+		if err != nil {
+			return Redirect{}, false, fmt.Errorf("%s: неверный дескриптор", tok.Text)
+		}
+		fd = n
+	}
+
+	op, ok := p.accept(Less, Great, DGreat, LessAnd, GreatAnd)
+	if !ok {
+		// лексер ставит IONumber только перед < и >, так что сюда попадаем без номера
+		p.pos = start
+		return Redirect{}, false, nil
+	}
+	if fd < 0 {
+		fd = DefaultFd(op.Kind)
+	}
+
+	target, ok := p.accept(Word)
+	if !ok {
+		if tok, ok := p.peek(); ok {
+			return Redirect{}, false, unexpected(tok)
+		}
+		return Redirect{}, false, errUnexpectedEnd
+	}
+
+	return Redirect{Fd: fd, Op: op.Kind, Target: target.Text}, true, nil
+}
+
+// unexpected - ошибка в стиле bash: syntax error near unexpected token
+func unexpected(tok Token) error {
+	return fmt.Errorf("синтаксическая ошибка рядом с неожиданным маркером `%s'", tok.Text)
+}
@@ -0,0 +1,253 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"L2_15/internal/myminishell/commands"
+	"L2_15/internal/myminishell/parser"
+)
+
+// stage - запущенная команда конвейера
+type stage struct {
+	pid    int      // внешний процесс, 0 - не запущен
+	done   chan int // код встроенной команды, которая выполняется в горутине
+	status int      // код команды, которая не запустилась
+}
+
+// runJob - горутина задания: конвейеры and-or списка по очереди
+func (s *Shell) runJob(j *job, andOr *parser.AndOr) {
+	status, sig := 0, os.Signal(nil)
+
+	for i, pipeline := range andOr.Pipelines {
+		// && выполняет конвейер после успеха, || - после ошибки, пропущенный конвейер код не меняет
+		if i > 0 && (andOr.Ops[i-1] == parser.And) != (status == 0) {
+			continue
+		}
+
+		status, sig = s.runPipeline(j, pipeline)
+		if sig == os.Interrupt {
+			break
+		}
+	}
+
+	j.finish(status, sig)
+}
+
+// runPipeline - запускаем все команды конвейера и ждем их. Код конвейера - код последней команды.
+// Встроенные команды работают в горутинах шелла, внешние - в одной группе процессов
+func (s *Shell) runPipeline(j *job, pipeline *parser.Pipeline) (int, os.Signal) {
+	n := len(pipeline.Commands)
+	subshell := j.subshell || n > 1
+
+	stdin := s.stdin
+	// без управления заданиями фоновое задание не должно читать терминал
+	var devNull *os.File
+	if j.subshell && s.term == nil {
+		f, err := os.Open(os.DevNull)
+		if err != nil {
+			fmt.Fprintln(s.stderr, "minishell:", err)
+			return 1, nil
+		}
+		stdin, devNull = f, f
+	}
+
+	// pipes[i] соединяет команды i и i+1
+	pipes := make([][2]*os.File, n-1)
+	for i := range pipes {
+		r, w, err := os.Pipe()
+		if err != nil {
+			fmt.Fprintln(s.stderr, "minishell:", err)
+			for _, p := range pipes[:i] {
+				closeAll(p[:])
+			}
+			closeAll([]*os.File{devNull})
+			return 1, nil
+		}
+		pipes[i] = [2]*os.File{r, w}
+	}
+
+	stages := make([]stage, n)
+	var pids []int
+	pgid := 0
+
+	for i, command := range pipeline.Commands {
+		files := s.stdio()
+		files[0] = stdin
+		// свои концы пайпов команда закрывает после запуска или, если она встроенная, после выполнения
+		var owned []*os.File
+		if i == 0 && devNull != nil {
+			owned = append(owned, devNull)
+		}
+		if i > 0 {
+			files[0] = pipes[i-1][0]
+			owned = append(owned, files[0])
+		}
+		if i < n-1 {
+			files[1] = pipes[i][1]
+			owned = append(owned, files[1])
+		}
+
+		stages[i] = s.startCommand(j, command, files, owned, subshell, pgid)
+		if pid := stages[i].pid; pid > 0 {
+			pids = append(pids, pid)
+			if s.term != nil && pgid == 0 {
+				pgid = pid
+			}
+		}
+	}
+	j.setProcesses(pgid, pids)
+
+	status, sig := 0, os.Signal(nil)
+	for _, st := range stages {
+		switch {
+		case st.pid > 0:
+			status, sig = waitProcess(st.pid, j.stopped)
+		case st.done != nil:
+			status, sig = <-st.done, nil
+		default:
+			status, sig = st.status, nil
+		}
+	}
+
+	return status, sig
+}
+
+// startCommand - запускаем команду конвейера с потоками files. Файлы owned принадлежат команде:
+// для внешней они закрываются сразу после запуска, у процесса свои копии, для встроенной - по ее завершении
+func (s *Shell) startCommand(j *job, command *parser.Command, files [3]*os.File, owned []*os.File, subshell bool, pgid int) stage {
+	opened, err := redirect(&files, command.Redirects)
+	owned = append(owned, opened...)
+	if err != nil {
+		closeAll(owned)
+		fmt.Fprintln(s.stderr, "minishell:", err)
+		return stage{status: 1}
+	}
+
+	// команда из одних перенаправлений только создает файлы
+	if len(command.Args) == 0 {
+		closeAll(owned)
+		return stage{}
+	}
+
+	if builtin, ok := s.builtin(command.Args[0]); ok {
+		done := make(chan int, 1)
+		go func() {
+			defer closeAll(owned)
+			done <- s.builtinEnv(builtin, command.Args, files, subshell)
+		}()
+		return stage{done: done}
+	}
+
+	defer closeAll(owned)
+
+	path, err := lookPath(command.Args[0])
+	if err != nil {
+		fmt.Fprintf(s.stderr, "%s: команда не найдена\n", command.Args[0])
+		return stage{status: 127}
+	}
+
+	// терминал забирает первый процесс конвейера, остальные входят в его группу
+	pid, err := startProcess(path, command.Args, files, s.term, pgid, j.isForeground() && pgid == 0)
+	if err != nil {
+		fmt.Fprintf(s.stderr, "%s: %v\n", command.Args[0], err)
+		return stage{status: 126}
+	}
+
+	return stage{pid: pid}
+}
+
+// runBuiltin - одна встроенная команда с перенаправлениями в текущей горутине
+func (s *Shell) runBuiltin(builtin commands.Builtin, command *parser.Command, files [3]*os.File, subshell bool) int {
+	opened, err := redirect(&files, command.Redirects)
+	defer closeAll(opened)
+	if err != nil {
+		fmt.Fprintln(s.stderr, "minishell:", err)
+		return 1
+	}
+
+	return s.builtinEnv(builtin, command.Args, files, subshell)
+}
+
+// builtinEnv - встроенная команда с потоками files
+func (s *Shell) builtinEnv(builtin commands.Builtin, args []string, files [3]*os.File, subshell bool) int {
+	return builtin(commands.Env{In: files[0], Out: files[1], Err: files[2], Subshell: subshell}, args)
+}
+
+// builtin - встроенная команда шелла или из commands
+func (s *Shell) builtin(name string) (commands.Builtin, bool) {
+	if builtin, ok := s.builtins[name]; ok {
+		return builtin, true
+	}
+	return commands.Lookup(name)
+}
+
+// stdio - стандартные потоки шелла
+func (s *Shell) stdio() [3]*os.File {
+	return [3]*os.File{s.stdin, s.stdout, s.stderr}
+}
+
+// redirect - применяем перенаправления по порядку, как sh: в "2>&1 >file" stderr идет
+// туда, куда stdout указывал до >file. Открытые файлы возвращаются, чтобы их закрыть
+func redirect(files *[3]*os.File, redirects []parser.Redirect) ([]*os.File, error) {
+	var opened []*os.File
+
+	for _, r := range redirects {
+		if r.Fd < 0 || r.Fd >= len(files) {
+			return opened, fmt.Errorf("%d: неверный дескриптор", r.Fd)
+		}
+
+		var f *os.File
+		var err error
+		switch r.Op {
+		case parser.Less:
+			f, err = os.Open(r.Target)
+		case parser.Great:
+			f, err = os.Create(r.Target)
+		case parser.DGreat:
+			f, err = os.OpenFile(r.Target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o666)
+		case parser.LessAnd, parser.GreatAnd:
+			n, convErr := strconv.Atoi(r.Target)
+			if convErr != nil || n < 0 || n >= len(files) {
+				return opened, fmt.Errorf("%s: неверный дескриптор", r.Target)
+			}
+			files[r.Fd] = files[n]
+			continue
+		}
+
+		if err != nil {
+			// без "open имя:", имя добавляем сами
+			var pathErr *fs.PathError
+			if errors.As(err, &pathErr) {
+				err = pathErr.Err
+			}
+			return opened, fmt.Errorf("%s: %w", r.Target, err)
+		}
+
+		opened = append(opened, f)
+		files[r.Fd] = f
+	}
+
+	return opened, nil
+}
+
+// lookPath - путь к команде. Команда из текущей папки через PATH с "." разрешена, как в sh
+func lookPath(name string) (string, error) {
+	path, err := exec.LookPath(name)
+	if errors.Is(err, exec.ErrDot) {
+		err = nil
+	}
+	return path, err
+}
+
+func closeAll(files []*os.File) {
+	for _, f := range files {
+		if f != nil {
+			_ = f.Close()
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"L2_15/internal/myminishell/commands"
+)
+
+// jobState - состояние задания
+type jobState int
+
+const (
+	running jobState = iota
+	stopped
+	done
+)
+
+// job - задание: and-or список, запущенный целиком на переднем плане или в фоне.
+// Конвейеры списка выполняются по очереди в горутине задания, у каждого своя группа процессов
+type job struct {
+	id   int // номер в таблице заданий, 0 - задание на переднем плане, в таблицу не попадало
+	text string
+	// subshell - задание запущено с &: как в bash, его команды не меняют состояние шелла
+	subshell bool
+
+	mu         sync.Mutex
+	state      jobState
+	foreground bool      // терминал у задания, новые конвейеры забирают его себе
+	pgid       int       // группа текущего конвейера, 0 - своей группы нет
+	pids       []int     // процессы текущего конвейера
+	lastPid    int       // последний процесс первого конвейера, для "[1] pid"
+	status     int       // код выхода последнего конвейера
+	signal     os.Signal // сигнал, которым убит последний конвейер, nil - завершился сам
+	reported   bool      // пользователь уже видел текущее состояние
+
+	changed   chan struct{} // буфер 1: состояние изменилось
+	started   chan struct{} // закрыт, когда запущен первый конвейер
+	startOnce sync.Once
+}
+
+func newJob(text string, foreground bool) *job {
+	return &job{
+		text:       text,
+		subshell:   !foreground,
+		foreground: foreground,
+		changed:    make(chan struct{}, 1),
+		started:    make(chan struct{}),
+	}
+}
+
+// setState - новое состояние задания. Завершенное задание уже не меняется
+func (j *job) setState(state jobState) {
+	j.mu.Lock()
+	if j.state != done {
+		j.state = state
+		j.reported = false
+	}
+	j.mu.Unlock()
+
+	select {
+	case j.changed <- struct{}{}:
+	default:
+	}
+}
+
+// stopped - колбэк waitProcess: процесс задания остановлен или продолжен
+func (j *job) stopped(yes bool) {
+	if yes {
+		j.setState(stopped)
+	} else {
+		j.setState(running)
+	}
+}
+
+// setProcesses - запущены процессы очередного конвейера
+func (j *job) setProcesses(pgid int, pids []int) {
+	j.mu.Lock()
+	j.pgid, j.pids = pgid, pids
+	if len(pids) > 0 && j.lastPid == 0 {
+		j.lastPid = pids[len(pids)-1]
+	}
+	j.mu.Unlock()
+
+	j.startOnce.Do(func() { close(j.started) })
+}
+
+// processes - группа и процессы текущего конвейера
+func (j *job) processes() (int, []int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.pgid, j.pids
+}
+
+// setForeground - задание получило терминал или ушло в фон
+func (j *job) setForeground(foreground bool) {
+	j.mu.Lock()
+	j.foreground = foreground
+	j.mu.Unlock()
+}
+
+func (j *job) isForeground() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.foreground
+}
+
+// finish - все конвейеры выполнены, status и signal - результат последнего
+func (j *job) finish(status int, signal os.Signal) {
+	j.mu.Lock()
+	j.status, j.signal = status, signal
+	j.pgid, j.pids = 0, nil
+	j.mu.Unlock()
+
+	j.setState(done)
+	j.startOnce.Do(func() { close(j.started) })
+}
+
+// result - код выхода и сигнал завершенного задания
+func (j *job) result() (int, os.Signal) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status, j.signal
+}
+
+// wait - ждем, пока задание не остановится или не завершится
+func (j *job) wait() jobState {
+	for {
+		j.mu.Lock()
+		state := j.state
+		j.mu.Unlock()
+
+		if state != running {
+			return state
+		}
+		<-j.changed
+	}
+}
+
+// report - состояние для уведомления. false - о нем уже сообщали
+func (j *job) report() (jobState, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.reported {
+		return j.state, false
+	}
+	j.reported = true
+	return j.state, true
+}
+
+// describe - состояние задания для jobs: Running, Stopped, Done, Exit 1, Killed...
+func (j *job) describe() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch {
+	case j.state == running:
+		return "Running"
+	case j.state == stopped:
+		return "Stopped"
+	case j.signal != nil:
+		name := j.signal.String()
+		return strings.ToUpper(name[:1]) + name[1:]
+	case j.status != 0:
+		return "Exit " + strconv.Itoa(j.status)
+	default:
+		return "Done"
+	}
+}
+
+// commandLine - текст задания, фоновое выполняющееся задание с &
+func (j *job) commandLine() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.state == running && !j.foreground {
+		return j.text + " &"
+	}
+	return j.text
+}
+
+// jobTable - фоновые и остановленные задания
+type jobTable struct {
+	mu sync.Mutex
+	// jobs - в порядке, в котором задания становились текущими: последнее - текущее (+),
+	// предпоследнее - предыдущее (-)
+	jobs []*job
+}
+
+// add - задание становится текущим. Новое задание получает номер больше всех занятых
+func (t *jobTable) add(j *job) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if j.id == 0 {
+		j.id = 1
+		for _, other := range t.jobs {
+			j.id = max(j.id, other.id+1)
+		}
+	}
+
+	t.jobs = slices.DeleteFunc(t.jobs, func(other *job) bool { return other == j })
+	t.jobs = append(t.jobs, j)
+}
+
+func (t *jobTable) remove(j *job) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.jobs = slices.DeleteFunc(t.jobs, func(other *job) bool { return other == j })
+}
+
+// list - задания по возрастанию номера
+func (t *jobTable) list() []*job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	jobs := slices.Clone(t.jobs)
+	slices.SortFunc(jobs, func(a, b *job) int { return a.id - b.id })
+	return jobs
+}
+
+// mark - + у текущего задания, - у предыдущего
+func (t *jobTable) mark(j *job) byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch n := len(t.jobs); {
+	case n > 0 && t.jobs[n-1] == j:
+		return '+'
+	case n > 1 && t.jobs[n-2] == j:
+		return '-'
+	default:
+		return ' '
+	}
+}
+
+// errNoCurrentJob - fg и bg без аргумента, а заданий нет
+var errNoCurrentJob = errors.New("текущее задание отсутствует")
+
+// find - задание по спецификации, как в bash: пусто, %, %% и %+ - текущее, %- - предыдущее, %N и N - по номеру
+func (t *jobTable) find(spec string) (*job, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(t.jobs)
+	switch spec {
+	case "", "%", "%%", "%+":
+		if n == 0 {
+			return nil, errNoCurrentJob
+		}
+		return t.jobs[n-1], nil
+	case "%-":
+		if n == 0 {
+			return nil, errNoCurrentJob
+		}
+		return t.jobs[max(n-2, 0)], nil
+	}
+
+	if id, err := strconv.Atoi(strings.TrimPrefix(spec, "%")); err == nil {
+		for _, j := range t.jobs {
+			if j.id == id {
+				return j, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%s: нет такого задания", spec)
+}
+
+// printJob - строка задания в формате bash: [1]+  Running                 sleep 10 &
+func (s *Shell) printJob(w io.Writer, j *job) {
+	fmt.Fprintf(w, "[%d]%c  %-24s%s\n", j.id, s.jobs.mark(j), j.describe(), j.commandLine())
+}
+
+// jobSpec - первый аргумент встроенной команды или пусто
+func jobSpec(args []string) string {
+	if len(args) < 2 {
+		return ""
+	}
+	return args[1]
+}
+
+// runJobs - список заданий. Завершенные задания показываются последний раз и удаляются
+func (s *Shell) runJobs(env commands.Env, _ []string) int {
+	for _, j := range s.jobs.list() {
+		state, _ := j.report()
+		s.printJob(env.Out, j)
+		if state == done {
+			s.jobs.remove(j)
+		}
+	}
+
+	return 0
+}
+
+// runFg - вернуть задание на передний план и продолжить, если оно остановлено
+func (s *Shell) runFg(env commands.Env, args []string) int {
+	if env.Subshell {
+		fmt.Fprintln(env.Err, "fg: нет управления заданиями")
+		return 1
+	}
+
+	j, err := s.jobs.find(jobSpec(args))
+	if err != nil {
+		fmt.Fprintf(env.Err, "fg: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(env.Out, j.text)
+
+	status, _ := s.waitForeground(j, true)
+	return status
+}
+
+// runBg - продолжить остановленное задание в фоне
+func (s *Shell) runBg(env commands.Env, args []string) int {
+	if env.Subshell {
+		fmt.Fprintln(env.Err, "bg: нет управления заданиями")
+		return 1
+	}
+
+	j, err := s.jobs.find(jobSpec(args))
+	if err != nil {
+		fmt.Fprintf(env.Err, "bg: %v\n", err)
+		return 1
+	}
+
+	j.mu.Lock()
+	state := j.state
+	j.mu.Unlock()
+	if state != stopped {
+		fmt.Fprintf(env.Err, "bg: задание %d уже выполняется в фоне\n", j.id)
+		return 0
+	}
+
+	j.setForeground(false)
+	j.setState(running)
+	pgid, pids := j.processes()
+	if err = continueProcesses(pgid, pids); err != nil {
+		fmt.Fprintf(env.Err, "bg: %v\n", err)
+		return 1
+	}
+
+	s.jobs.add(j)
+	fmt.Fprintf(env.Out, "[%d]%c %s\n", j.id, s.jobs.mark(j), j.commandLine())
+
+	return 0
+}
+
+// runKill - kill из commands, но с заданием вида %N убивает всю его группу процессов
+func (s *Shell) runKill(env commands.Env, args []string) int {
+	if len(args) < 2 || !strings.HasPrefix(args[1], "%") {
+		kill, _ := commands.Lookup("kill")
+		return kill(env, args)
+	}
+
+	j, err := s.jobs.find(args[1])
+	if err != nil {
+		fmt.Fprintf(env.Err, "kill: %v\n", err)
+		return 1
+	}
+
+	pgid, pids := j.processes()
+	if err = killProcesses(pgid, pids); err != nil {
+		fmt.Fprintf(env.Err, "kill: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(env.Out, "Процесс %v успешно завершен\n", args[1])
+
+	return 0
+}
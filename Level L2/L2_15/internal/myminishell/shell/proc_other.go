@@ -0,0 +1,64 @@
+//go:build !linux && !darwin
+
+package shell
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// stoppedStatus - задания здесь не останавливаются, код только для общего кода
+const stoppedStatus = 148
+
+// terminal - без групп процессов управления заданиями нет: Ctrl-C получают все, fg только ждет
+type terminal struct{}
+
+func newTerminal(*os.File) *terminal { return nil }
+
+func (t *terminal) give(int) error { return nil }
+
+func (t *terminal) reclaim() {}
+
+// processes - запущенные процессы по pid, чтобы дождаться и убить их по номеру
+var processes sync.Map
+
+// startProcess - запускаем внешнюю команду с дескрипторами files
+func startProcess(path string, args []string, files [3]*os.File, _ *terminal, _ int, _ bool) (int, error) {
+	proc, err := os.StartProcess(path, args, &os.ProcAttr{Files: files[:]})
+	if err != nil {
+		return 0, err
+	}
+	processes.Store(proc.Pid, proc)
+
+	return proc.Pid, nil
+}
+
+// waitProcess - ждем завершения процесса, остановок здесь не бывает
+func waitProcess(pid int, _ func(bool)) (int, os.Signal) {
+	value, ok := processes.LoadAndDelete(pid)
+	if !ok {
+		return 1, nil
+	}
+
+	state, err := value.(*os.Process).Wait()
+	if err != nil {
+		return 1, nil
+	}
+
+	return state.ExitCode(), nil
+}
+
+// continueProcesses - остановленных процессов не бывает
+func continueProcesses(int, []int) error { return nil }
+
+// killProcesses - убить процессы задания
+func killProcesses(_ int, pids []int) error {
+	var err error
+	for _, pid := range pids {
+		if value, ok := processes.Load(pid); ok {
+			err = errors.Join(err, value.(*os.Process).Kill())
+		}
+	}
+	return err
+}
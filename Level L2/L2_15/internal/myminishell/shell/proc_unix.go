@@ -0,0 +1,172 @@
+//go:build linux || darwin
+
+package shell
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// stoppedStatus - код выхода задания, остановленного Ctrl-Z, как в bash
+const stoppedStatus = 128 + int(syscall.SIGTSTP)
+
+// ttyMu - пока терминал отдается и забирается, SIGTTOU игнорируется, а игнорирование
+// наследуется через exec. Запуск процессов ждет, чтобы дети не получили его по наследству
+var ttyMu sync.Mutex
+
+// terminal - управляющий терминал интерактивного шелла
+type terminal struct {
+	fd   int
+	pgid int // группа самого шелла
+	// ttou - вне give SIGTTOU перехвачен: перехваченный сигнал у запущенных команд
+	// сбрасывается по умолчанию, а игнорируемый они бы унаследовали
+	ttou chan os.Signal
+}
+
+// newTerminal - включаем управление заданиями, если stdin - терминал и шелл на переднем плане.
+// Шелл переходит в свою группу процессов, а Ctrl-C, Ctrl-\ и Ctrl-Z в нем перехватываются:
+// до внешних команд они доходят сами, потому что терминал отдается группе задания.
+// nil - управления заданиями нет, все процессы остаются в группе шелла
+func newTerminal(f *os.File) *terminal {
+	fd := int(f.Fd())
+
+	foreground, err := tcgetpgrp(fd)
+	if err != nil || foreground != syscall.Getpgrp() {
+		return nil
+	}
+
+	pid := syscall.Getpid()
+	if syscall.Getpgrp() != pid {
+		if err = syscall.Setpgid(0, 0); err != nil {
+			return nil
+		}
+	}
+	t := &terminal{fd: fd, pgid: pid, ttou: make(chan os.Signal, 1)}
+	if err = t.give(pid); err != nil {
+		return nil
+	}
+
+	// Notify, а не Ignore: перехваченные сигналы у детей сбрасываются по умолчанию,
+	// а проигнорированные так и остались бы проигнорированными
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTSTP)
+	go func() {
+		for sig := range sigs {
+			// Ctrl-C на пустой строке: терминал сам выбросил набранное, переводим строку
+			if sig == syscall.SIGINT {
+				os.Stdout.WriteString("\n")
+			}
+		}
+	}()
+
+	return t
+}
+
+// give - отдаем терминал группе pgid
+func (t *terminal) give(pgid int) error {
+	ttyMu.Lock()
+	defer ttyMu.Unlock()
+
+	// из фоновой группы tcsetpgrp присылает SIGTTOU, если он не игнорируется.
+	// Потом снова перехватываем его, Reset оставил бы его игнорируемым
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Notify(t.ttou, syscall.SIGTTOU)
+
+	return tcsetpgrp(t.fd, pgid)
+}
+
+// reclaim - возвращаем терминал шеллу
+func (t *terminal) reclaim() {
+	_ = t.give(t.pgid)
+}
+
+func tcgetpgrp(fd int) (int, error) {
+	var pgid int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCGPGRP), uintptr(unsafe.Pointer(&pgid))); errno != 0 {
+		return 0, errno
+	}
+	return int(pgid), nil
+}
+
+func tcsetpgrp(fd, pgid int) error {
+	pg := int32(pgid)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCSPGRP), uintptr(unsafe.Pointer(&pg))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// startProcess - запускаем внешнюю команду с дескрипторами files. С управлением заданиями
+// процесс попадает в группу pgid (0 - новая группа с ним во главе), а foreground отдает ей терминал
+func startProcess(path string, args []string, files [3]*os.File, t *terminal, pgid int, foreground bool) (int, error) {
+	attr := &syscall.ProcAttr{
+		Env:   os.Environ(),
+		Files: []uintptr{files[0].Fd(), files[1].Fd(), files[2].Fd()},
+	}
+	if t != nil {
+		// терминал отдает сам ребенок до exec, пока сигналы в нем заблокированы
+		attr.Sys = &syscall.SysProcAttr{Setpgid: true, Pgid: pgid, Foreground: foreground, Ctty: t.fd}
+	}
+
+	ttyMu.Lock()
+	defer ttyMu.Unlock()
+
+	pid, err := syscall.ForkExec(path, args, attr)
+	runtime.KeepAlive(files)
+
+	return pid, err
+}
+
+// waitProcess - ждем завершения процесса. Остановки и продолжения по дороге передаются в stopped.
+// sig - сигнал, которым процесс убит, nil - процесс завершился сам
+func waitProcess(pid int, stopped func(bool)) (status int, sig os.Signal) {
+	for {
+		var ws syscall.WaitStatus
+		_, err := syscall.Wait4(pid, &ws, syscall.WUNTRACED|syscall.WCONTINUED, nil)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil {
+			return 1, nil
+		}
+
+		switch {
+		case ws.Exited():
+			return ws.ExitStatus(), nil
+		case ws.Signaled():
+			return 128 + int(ws.Signal()), ws.Signal()
+		case ws.Stopped():
+			stopped(true)
+		case ws.Continued():
+			stopped(false)
+		}
+	}
+}
+
+// signalProcesses - сигнал всей группе pgid, а без своей группы - каждому процессу
+func signalProcesses(pgid int, pids []int, sig syscall.Signal) error {
+	if pgid > 0 {
+		return syscall.Kill(-pgid, sig)
+	}
+
+	var err error
+	for _, pid := range pids {
+		err = errors.Join(err, syscall.Kill(pid, sig))
+	}
+	return err
+}
+
+// continueProcesses - продолжить остановленное задание
+func continueProcesses(pgid int, pids []int) error {
+	return signalProcesses(pgid, pids, syscall.SIGCONT)
+}
+
+// killProcesses - убить задание, как kill для одного процесса
+func killProcesses(pgid int, pids []int) error {
+	return signalProcesses(pgid, pids, syscall.SIGKILL)
+}
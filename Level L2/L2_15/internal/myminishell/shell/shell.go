@@ -0,0 +1,187 @@
+// Package shell - выполнение разобранных строк: конвейеры, перенаправления, && и ||,
+// фоновые задания и управление ими через jobs, fg и bg
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"L2_15/internal/myminishell/commands"
+	"L2_15/internal/myminishell/parser"
+)
+
+// Shell - состояние шелла: таблица заданий, терминал и код последней команды
+type Shell struct {
+	stdin, stdout, stderr *os.File
+
+	term     *terminal // nil - stdin не терминал, заданиями управлять нельзя
+	jobs     jobTable
+	builtins map[string]commands.Builtin // встроенные команды, которым нужно состояние шелла
+
+	status int
+	exited bool
+}
+
+// New - шелл на потоках stdin, stdout и stderr. Если stdin - терминал, включается
+// управление заданиями: у каждого конвейера своя группа процессов
+func New(stdin, stdout, stderr *os.File) *Shell {
+	s := &Shell{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		term:   newTerminal(stdin),
+	}
+
+	s.builtins = map[string]commands.Builtin{
+		"jobs": s.runJobs,
+		"fg":   s.runFg,
+		"bg":   s.runBg,
+		"kill": s.runKill,
+		"exit": s.runExit,
+	}
+
+	return s
+}
+
+// Status - код выхода последней команды
+func (s *Shell) Status() int {
+	return s.status
+}
+
+// Exited - выполнен exit
+func (s *Shell) Exited() bool {
+	return s.exited
+}
+
+// Run - выполнить строку. Ошибки разбора и команд печатаются в stderr, код последней команды - в Status
+func (s *Shell) Run(line string) {
+	list, err := parser.Parse(line)
+	if err != nil {
+		fmt.Fprintln(s.stderr, "minishell:", err)
+		s.status = 2
+		return
+	}
+
+	for _, item := range list.Items {
+		if item.Background {
+			s.background(item.AndOr)
+			continue
+		}
+
+		var sig os.Signal
+		s.status, sig = s.foreground(item.AndOr)
+
+		// как и bash, после Ctrl-C остаток строки не выполняем
+		if sig == os.Interrupt || s.exited {
+			return
+		}
+	}
+}
+
+// ReportJobs - сообщаем о фоновых заданиях, которые завершились или остановились
+// с прошлого раза. Как и bash, шелл делает это перед чтением следующей строки
+func (s *Shell) ReportJobs() {
+	for _, j := range s.jobs.list() {
+		state, fresh := j.report()
+		if !fresh || state == running {
+			continue
+		}
+
+		s.printJob(s.stderr, j)
+		if state == done {
+			s.jobs.remove(j)
+		}
+	}
+}
+
+// foreground - выполнить and-or список и дождаться его
+func (s *Shell) foreground(andOr *parser.AndOr) (int, os.Signal) {
+	// одна встроенная команда выполняется прямо в шелле, без задания
+	if len(andOr.Pipelines) == 1 && len(andOr.Pipelines[0].Commands) == 1 {
+		command := andOr.Pipelines[0].Commands[0]
+		if len(command.Args) > 0 {
+			if builtin, ok := s.builtin(command.Args[0]); ok {
+				return s.runBuiltin(builtin, command, s.stdio(), false), nil
+			}
+		}
+	}
+
+	j := newJob(andOr.String(), true)
+	go s.runJob(j, andOr)
+
+	return s.waitForeground(j, false)
+}
+
+// background - запустить and-or список фоновым заданием
+func (s *Shell) background(andOr *parser.AndOr) {
+	j := newJob(andOr.String(), false)
+	s.jobs.add(j)
+	go s.runJob(j, andOr)
+
+	<-j.started
+	j.mu.Lock()
+	pid := j.lastPid
+	j.mu.Unlock()
+
+	if pid > 0 {
+		fmt.Fprintf(s.stderr, "[%d] %d\n", j.id, pid)
+	} else {
+		fmt.Fprintf(s.stderr, "[%d]\n", j.id)
+	}
+	s.status = 0
+}
+
+// waitForeground - отдаем заданию терминал и ждем, пока оно не завершится или не остановится.
+// cont - задание остановлено, его нужно продолжить
+func (s *Shell) waitForeground(j *job, cont bool) (int, os.Signal) {
+	j.setForeground(true)
+	pgid, pids := j.processes()
+	if s.term != nil && pgid > 0 {
+		_ = s.term.give(pgid)
+	}
+
+	if cont {
+		j.setState(running)
+		if err := continueProcesses(pgid, pids); err != nil {
+			fmt.Fprintln(s.stderr, "minishell:", err)
+		}
+	}
+
+	state := j.wait()
+	if s.term != nil {
+		s.term.reclaim()
+	}
+
+	if state == stopped {
+		// остановленное Ctrl-Z задание попадает в таблицу и становится текущим
+		j.setForeground(false)
+		s.jobs.add(j)
+		j.report()
+		fmt.Fprintln(s.stderr)
+		s.printJob(s.stderr, j)
+		return stoppedStatus, nil
+	}
+
+	s.jobs.remove(j)
+	return j.result()
+}
+
+// runExit - exit [код]. В конвейере и в фоне шелл не завершается, как exit в подоболочке bash
+func (s *Shell) runExit(env commands.Env, args []string) int {
+	status := s.status
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Fprintf(env.Err, "exit: %s: требуется числовой аргумент\n", args[1])
+			n = 2
+		}
+		status = n & 0xff
+	}
+
+	if !env.Subshell {
+		s.exited = true
+	}
+
+	return status
+}
@@ -2,37 +2,28 @@ package reader
 
 import (
 	"bufio"
-	"fmt"
-	"os"
+	"io"
 	"strings"
-
-	"github.com/google/shlex"
 )
 
-// Read - читаем консоль
-func Read() ([][]string, bool) {
-	reader := bufio.NewReader(os.Stdin)
-	line, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, true
-	}
-
-	line = strings.TrimSpace(line)
-	line = strings.ReplaceAll(line, `\`, `\\`)
+// Reader - построчное чтение консоли. bufio.Reader один на все строки,
+// иначе прочитанный про запас хвост ввода терялся бы между вызовами
+type Reader struct {
+	r *bufio.Reader
+}
 
-	// Разбиваем по |
-	parts := strings.Split(line, "|")
-	commands := make([][]string, 0, len(parts))
+// New - читаем строки из r
+func New(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
 
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		args, err := shlex.Split(part)
-		if err != nil {
-			fmt.Println("Ошибка парсинга:", err)
-			return nil, false
-		}
-		commands = append(commands, args)
+// Read - читаем консоль: следующая строка без перевода строки. true - ввод закончился
+func (r *Reader) Read() (string, bool) {
+	line, err := r.r.ReadString('\n')
+	// последняя строка без \n тоже выполняется, конец ввода - на следующем вызове
+	if err != nil && line == "" {
+		return "", true
 	}
 
-	return commands, false
+	return strings.TrimRight(line, "\r\n"), false
 }
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"L2_15/internal/myminishell/parser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	word := func(text string) parser.Token { return parser.Token{Kind: parser.Word, Text: text} }
+	op := func(kind parser.TokenKind, text string) parser.Token { return parser.Token{Kind: kind, Text: text} }
+
+	tests := []struct {
+		line    string
+		want    []parser.Token
+		wantErr bool
+	}{
+		{line: "", want: nil},
+		{line: "  # только комментарий", want: nil},
+		{line: "echo a#b # c", want: []parser.Token{word("echo"), word("a#b")}},
+		{line: "ls -l|wc", want: []parser.Token{word("ls"), word("-l"), op(parser.Pipe, "|"), word("wc")}},
+		{line: "a&&b||c;d&", want: []parser.Token{
+			word("a"), op(parser.And, "&&"), word("b"), op(parser.Or, "||"), word("c"),
+			op(parser.Semicolon, ";"), word("d"), op(parser.Amp, "&"),
+		}},
+		{line: `echo 'a | b' "c \"d\" \x" e\ f ""`, want: []parser.Token{
+			word("echo"), word("a | b"), word(`c "d" \x`), word("e f"), word(""),
+		}},
+		// номер дескриптора - только цифры вплотную к редиректу и без кавычек
+		{line: "cmd 2>err 2 >out '2'>q", want: []parser.Token{
+			word("cmd"), op(parser.IONumber, "2"), op(parser.Great, ">"), word("err"),
+			word("2"), op(parser.Great, ">"), word("out"),
+			word("2"), op(parser.Great, ">"), word("q"),
+		}},
+		{line: "cmd >>log <in 2>&1 0<&3", want: []parser.Token{
+			word("cmd"), op(parser.DGreat, ">>"), word("log"), op(parser.Less, "<"), word("in"),
+			op(parser.IONumber, "2"), op(parser.GreatAnd, ">&"), word("1"),
+			op(parser.IONumber, "0"), op(parser.LessAnd, "<&"), word("3"),
+		}},
+		{line: "echo 'oops", wantErr: true},
+		{line: `echo "oops`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := parser.Tokenize(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Tokenize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// render - список одной строкой: and-or списки через "; ", фоновые заканчиваются на " &"
+func render(list *parser.List) string {
+	var b strings.Builder
+	for i, item := range list.Items {
+		if i > 0 && !list.Items[i-1].Background {
+			b.WriteString("; ")
+		} else if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(item.AndOr.String())
+		if item.Background {
+			b.WriteString(" &")
+		}
+	}
+	return b.String()
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{line: "", want: ""},
+		{line: "echo hi", want: "echo hi"},
+		{line: "a | b | c", want: "a | b | c"},
+		{line: "a && b || c", want: "a && b || c"},
+		{line: "a; b & c;", want: "a; b & c"},
+		{line: "sleep 1 &", want: "sleep 1 &"},
+		{line: "echo 'a b' > 'out file'", want: "echo 'a b' >'out file'"},
+		{line: "cmd 2>&1 >file <in", want: "cmd 2>&1 >file <in"},
+		{line: "cmd 1>out 0<in 3>>log", want: "cmd >out <in 3>>log"},
+		{line: "> empty", want: ">empty"},
+		{line: "a |", wantErr: true},
+		{line: "| a", wantErr: true},
+		{line: "a && && b", wantErr: true},
+		{line: "; a", wantErr: true},
+		{line: "cmd >", wantErr: true},
+		{line: "cmd > | b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			list, err := parser.Parse(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got := render(list)
+			if got != tt.want {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+
+			// вывод String разбирается обратно в то же дерево
+			again, err := parser.Parse(got)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", got, err)
+			}
+			if !reflect.DeepEqual(again, list) {
+				t.Errorf("Parse(%q) = %+v, want %+v", got, again, list)
+			}
+		})
+	}
+}
+
+func TestParseRedirects(t *testing.T) {
+	list, err := parser.Parse("cmd <in 2>err >>log 2>&1 a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	command := list.Items[0].AndOr.Pipelines[0].Commands[0]
+	if want := []string{"cmd", "a"}; !reflect.DeepEqual(command.Args, want) {
+		t.Errorf("Args = %q, want %q", command.Args, want)
+	}
+
+	want := []parser.Redirect{
+		{Fd: 0, Op: parser.Less, Target: "in"},
+		{Fd: 2, Op: parser.Great, Target: "err"},
+		{Fd: 1, Op: parser.DGreat, Target: "log"},
+		{Fd: 2, Op: parser.GreatAnd, Target: "1"},
+	}
+	if !reflect.DeepEqual(command.Redirects, want) {
+		t.Errorf("Redirects = %+v, want %+v", command.Redirects, want)
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"L2_15/internal/myminishell/shell"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// run - выполняем строки в новом шелле в пустом каталоге, stdin не терминал.
+// Возвращаем stdout, stderr и код последней команды
+func run(t *testing.T, lines ...string) (string, string, int) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+
+	stdout, stderr := tempFile(t, "stdout"), tempFile(t, "stderr")
+	sh := shell.New(stdin, stdout, stderr)
+	for _, line := range lines {
+		sh.Run(line)
+	}
+
+	return readFile(t, stdout.Name()), readFile(t, stderr.Name()), sh.Status()
+}
+
+func tempFile(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestShellRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []string
+		wantOut    string
+		wantErr    string
+		wantStatus int
+	}{
+		{name: "builtin", lines: []string{"echo hello world"}, wantOut: "hello world\n"},
+		{name: "pipeline builtin to external", lines: []string{"echo hello | tr a-z A-Z"}, wantOut: "HELLO\n"},
+		{name: "pipeline of externals", lines: []string{"printf 'b\\na\\nc\\n' | sort | head -n 2"}, wantOut: "a\nb\n"},
+		{name: "pipeline status is the last command", lines: []string{"false | true"}, wantStatus: 0},
+		{name: "and skips after failure", lines: []string{"false && echo no"}, wantStatus: 1},
+		{name: "or runs after failure", lines: []string{"false || echo yes"}, wantOut: "yes\n"},
+		{name: "and-or chain", lines: []string{"true && false || echo fallback && echo end"}, wantOut: "fallback\nend\n"},
+		{name: "sequence", lines: []string{"echo a; echo b"}, wantOut: "a\nb\n"},
+		{
+			name:    "output and input redirects",
+			lines:   []string{"echo one > f", "echo two >> f", "tr a-z A-Z < f"},
+			wantOut: "ONE\nTWO\n",
+		},
+		{
+			name:    "redirect into pipeline",
+			lines:   []string{"printf 'x\\ny\\n' > f", "cat < f | wc -l | tr -d ' '"},
+			wantOut: "2\n",
+		},
+		{
+			// 2>&1 раньше >file: stderr остается там, куда stdout указывал до >file
+			name:    "dup order",
+			lines:   []string{"ls missing 2>&1 >f | wc -l | tr -d ' '", "cat f"},
+			wantOut: "1\n",
+		},
+		{name: "stderr to file", lines: []string{"ls missing 2>err", "wc -l < err | tr -d ' '"}, wantOut: "1\n"},
+		{name: "redirect only creates file", lines: []string{"> empty", "ls"}, wantOut: "empty\n"},
+		{name: "missing input file", lines: []string{"cat < nope"}, wantErr: "minishell: nope: no such file or directory\n", wantStatus: 1},
+		{name: "unknown command", lines: []string{"no-such-command-xyz"}, wantErr: "no-such-command-xyz: команда не найдена\n", wantStatus: 127},
+		{name: "syntax error", lines: []string{"echo |"}, wantErr: "minishell: синтаксическая ошибка: неожиданный конец строки\n", wantStatus: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut, status := run(t, tt.lines...)
+			if out != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", out, tt.wantOut)
+			}
+			if errOut != tt.wantErr {
+				t.Errorf("stderr = %q, want %q", errOut, tt.wantErr)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
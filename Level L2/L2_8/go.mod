@@ -2,8 +2,9 @@ module l2_8
 
 go 1.24.5
 
+require github.com/beevik/ntp v1.4.3
+
 require (
-	github.com/beevik/ntp v1.4.3 // indirect
 	golang.org/x/net v0.25.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
 )
@@ -1,54 +1,29 @@
 package main
 
 import (
-	"errors"
 	"fmt"
-	"strconv"
-	"unicode"
+	"strings"
 )
 
-// UnpackingString - распаковка строк
+// UnpackingString - распаковка строк: a4bc2d5e -> aaaabccddddde, qwe\45 -> qwe44444
 func UnpackingString(str string) (string, error) {
-	// пустая строка
-	if str == "" {
-		return "", nil
-	}
+	var result strings.Builder
 
-	// проверка что только цифры
-	allDigits := true
-	for _, r := range str {
-		if !unicode.IsDigit(r) {
-			allDigits = false
-			break
-		}
-	}
-	if allDigits {
-		return "", errors.New("некорректная строка: только цифры")
+	if err := Unpack(strings.NewReader(str), &result); err != nil {
+		return "", err
 	}
 
-	runes := []rune(str)
-	var result []rune
-	var pred rune // предыдущий символ
+	return result.String(), nil
+}
 
-	for i := 0; i < len(runes); i++ {
-		r := runes[i] // текущий символ
+// PackString - упаковка строк, обратная UnpackingString: aaaabccddddde -> a4bc2d5e
+func PackString(str string) string {
+	var result strings.Builder
 
-		if unicode.IsDigit(r) {
-			// тут проверка на строку такого типа: 4a
-			if pred == 0 {
-				return "", errors.New("некорректная строка: цифра без символа")
-			}
-			num, _ := strconv.Atoi(string(r))
-			for j := 0; j < num-1; j++ {
-				result = append(result, pred)
-			}
-		} else {
-			result = append(result, r)
-			pred = r
-		}
-	}
+	// запись в strings.Builder не возвращает ошибок
+	_ = Pack(strings.NewReader(str), &result)
 
-	return string(result), nil
+	return result.String()
 }
 
 func main() {
@@ -58,10 +33,22 @@ func main() {
 		"45",
 		"",
 		"4a",
+		"a10",
+		`qwe\4\5`,
+		`qwe\45`,
+		`qwe\\5`,
+		"a99999999999",
 	}
 
 	for _, t := range tests {
 		res, err := UnpackingString(t)
 		fmt.Println("ввод:", t, "вывод: ", res, err)
+
+		// упаковка обратно дает строку, которая распаковывается в то же самое
+		if err == nil {
+			packed := PackString(res)
+			again, _ := UnpackingString(packed)
+			fmt.Println("  упаковка:", packed, "совпадает:", again == res)
+		}
 	}
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// maxRepeat - максимальное число повторов одного символа, защита от переполнения и огромного вывода
+const maxRepeat = 1_000_000
+
+var (
+	ErrDigitWithoutSymbol = errors.New("некорректная строка: цифра без символа")
+	ErrTrailingEscape     = errors.New("некорректная строка: \\ в конце строки")
+	ErrRepeatTooLarge     = fmt.Errorf("некорректная строка: число повторов больше %d", maxRepeat)
+)
+
+// Unpack - потоковая распаковка: читаем из r, пишем в w.
+// \ экранирует следующий символ, число после символа - сколько раз его повторить (0 - удалить).
+// При ошибке в w может остаться уже распакованная часть
+func Unpack(r io.Reader, w io.Writer) error {
+	in := bufio.NewReader(r)
+	out := bufio.NewWriter(w)
+
+	var (
+		pred     rune // символ, который ждет вывода
+		hasPred  bool
+		count    int // накопленное число повторов
+		hasCount bool
+	)
+
+	// flush - выводим предыдущий символ нужное число раз
+	flush := func() error {
+		if !hasPred {
+			return nil
+		}
+
+		n := 1
+		if hasCount {
+			n = count
+		}
+
+		for ; n > 0; n-- {
+			if _, err := out.WriteRune(pred); err != nil {
+				return err
+			}
+		}
+
+		hasPred, hasCount, count = false, false, 0
+
+		return nil
+	}
+
+	for {
+		ch, _, err := in.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case isDigit(ch):
+			if !hasPred {
+				return ErrDigitWithoutSymbol
+			}
+
+			count = count*10 + int(ch-'0')
+			hasCount = true
+			if count > maxRepeat {
+				return ErrRepeatTooLarge
+			}
+
+			continue
+
+		case ch == '\\':
+			next, _, err := in.ReadRune()
+			if errors.Is(err, io.EOF) {
+				return ErrTrailingEscape
+			}
+			if err != nil {
+				return err
+			}
+			ch = next
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+		pred, hasPred = ch, true
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return out.Flush()
+}
+
+// Pack - потоковая упаковка (run-length encoding), обратная к Unpack:
+// серия одинаковых символов превращается в символ и число, цифры и \ экранируются
+func Pack(r io.Reader, w io.Writer) error {
+	in := bufio.NewReader(r)
+	out := bufio.NewWriter(w)
+
+	var (
+		pred    rune
+		hasPred bool
+		run     int
+	)
+
+	flush := func() error {
+		if !hasPred {
+			return nil
+		}
+
+		if pred == '\\' || isDigit(pred) {
+			if err := out.WriteByte('\\'); err != nil {
+				return err
+			}
+		}
+
+		if _, err := out.WriteRune(pred); err != nil {
+			return err
+		}
+
+		if run > 1 {
+			if _, err := out.WriteString(strconv.Itoa(run)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for {
+		ch, _, err := in.ReadRune()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// длинные серии делим, чтобы результат всегда распаковывался
+		if hasPred && ch == pred && run < maxRepeat {
+			run++
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return err
+		}
+		pred, hasPred, run = ch, true, 1
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return out.Flush()
+}
+
+// isDigit - число повторов пишется только ASCII цифрами
+func isDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestUnpackingString(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "counts", input: "a4bc2d5e", want: "aaaabccddddde"},
+		{name: "no counts", input: "abcd", want: "abcd"},
+		{name: "empty", input: "", want: ""},
+		{name: "multi-digit count", input: "a10b", want: "aaaaaaaaaab"},
+		{name: "zero removes symbol", input: "ab0c", want: "ac"},
+		{name: "leading zero", input: "a03", want: "aaa"},
+		{name: "unicode", input: "ж3ё", want: "жжжё"},
+		{name: "escaped digits", input: `qwe\4\5`, want: "qwe45"},
+		{name: "escaped digit with count", input: `qwe\45`, want: "qwe44444"},
+		{name: "escaped backslash with count", input: `qwe\\5`, want: `qwe\\\\\`},
+		{name: "escaped letter", input: `\a2`, want: "aa"},
+		{name: "count at limit", input: "a1000000", want: strings.Repeat("a", maxRepeat)},
+		{name: "only digits", input: "45", wantErr: ErrDigitWithoutSymbol},
+		{name: "digit first", input: "4a", wantErr: ErrDigitWithoutSymbol},
+		{name: "trailing escape", input: `ab\`, wantErr: ErrTrailingEscape},
+		{name: "count over limit", input: "a1000001", wantErr: ErrRepeatTooLarge},
+		{name: "huge count", input: "a99999999999", wantErr: ErrRepeatTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnpackingString(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UnpackingString(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UnpackingString(%q) = %q, want %q", tt.input, shorten(got), shorten(tt.want))
+			}
+		})
+	}
+}
+
+func TestPackString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "aaaabccddddde", want: "a4bc2d5e"},
+		{input: "abcd", want: "abcd"},
+		{input: "", want: ""},
+		{input: "qwe44444", want: `qwe\45`},
+		{input: `a\\b`, want: `a\\2b`},
+		{input: "жжжё", want: "ж3ё"},
+		// серия длиннее maxRepeat делится на части
+		{input: strings.Repeat("a", maxRepeat+2), want: "a1000000a2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(shorten(tt.input), func(t *testing.T) {
+			if got := PackString(tt.input); got != tt.want {
+				t.Errorf("PackString(%q) = %q, want %q", shorten(tt.input), got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPackRoundTrip - упакованная строка распаковывается в исходную
+func TestPackRoundTrip(t *testing.T) {
+	inputs := []string{
+		"aaaabccddddde",
+		"abcd",
+		"",
+		"112223333",
+		`\\\a\\`,
+		"ёжжж  мм\n\n\t",
+		strings.Repeat("b", maxRepeat*2+5),
+	}
+
+	for _, input := range inputs {
+		t.Run(shorten(input), func(t *testing.T) {
+			packed := PackString(input)
+
+			got, err := UnpackingString(packed)
+			if err != nil {
+				t.Fatalf("UnpackingString(%q) error = %v", packed, err)
+			}
+			if got != input {
+				t.Errorf("round trip of %q = %q", shorten(input), shorten(got))
+			}
+		})
+	}
+}
+
+func TestUnpackStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		// руна и число повторов приходят по одному байту
+		{name: "byte by byte", input: "ж2\\34a10", want: "жж3333aaaaaaaaaa"},
+		{name: "error after output", input: "ab2\\", wantErr: ErrTrailingEscape},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			err := Unpack(iotest.OneByteReader(strings.NewReader(tt.input)), &out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unpack() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && out.String() != tt.want {
+				t.Errorf("Unpack() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	errRead := errors.New("read failed")
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{
+			name: "unpack read error",
+			run: func() error {
+				return Unpack(iotest.ErrReader(errRead), &strings.Builder{})
+			},
+			wantErr: errRead,
+		},
+		{
+			name: "unpack write error",
+			run: func() error {
+				return Unpack(strings.NewReader("a5"), failingWriter{errWrite})
+			},
+			wantErr: errWrite,
+		},
+		{
+			name: "pack read error",
+			run: func() error {
+				return Pack(iotest.ErrReader(errRead), &strings.Builder{})
+			},
+			wantErr: errRead,
+		},
+		{
+			name: "pack write error",
+			run: func() error {
+				return Pack(strings.NewReader("aaaaa"), failingWriter{errWrite})
+			},
+			wantErr: errWrite,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// failingWriter - вывод, в который нельзя записать
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+// shorten - длинные строки в именах подтестов и сообщениях обрезаем
+func shorten(s string) string {
+	if len(s) <= 32 {
+		return s
+	}
+
+	return s[:32] + "..."
+}
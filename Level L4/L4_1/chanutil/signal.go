@@ -0,0 +1,97 @@
+// Package chanutil - комбинаторы каналов на дженериках: Or, And, Merge, Tee, Bridge, OrDone, Take.
+//
+// Каждая функция запускает горутины, которые гарантированно завершаются: когда результат
+// готов, когда закрылись входные каналы или когда отменен контекст (варианты ...Context).
+// Вариант без контекста равен варианту с context.Background()
+package chanutil
+
+import "context"
+
+// orGroup - сколько каналов ждет одна горутина Or: select с фиксированным числом веток
+// быстрее reflect.Select, а недостающие каналы заменяются nil, который никогда не готов
+const orGroup = 4
+
+// Or - канал, который закрывается, как только любой из channels прислал значение или закрылся.
+// Без каналов результат не закрывается никогда
+func Or[T any](channels ...<-chan T) <-chan T {
+	return OrContext(context.Background(), channels...)
+}
+
+// OrContext - Or, который закрывается и при отмене ctx. После закрытия результата
+// все горутины Or завершены
+func OrContext[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	out := make(chan T)
+	done := ctx.Done()
+
+	if len(channels) == 0 {
+		if done != nil {
+			go func() {
+				<-done
+				close(out)
+			}()
+		}
+		return out
+	}
+
+	// stop закрывается первой сработавшей горутиной и будит остальные
+	stop := make(chan struct{})
+	signal := make(chan struct{}, 1)
+
+	for start := 0; start < len(channels); start += orGroup {
+		var group [orGroup]<-chan T
+		copy(group[:], channels[start:])
+
+		go func() {
+			select {
+			case <-group[0]:
+			case <-group[1]:
+			case <-group[2]:
+			case <-group[3]:
+			case <-done:
+			case <-stop:
+				return
+			}
+
+			// сигнал отдает только первая горутина, остальные уже выходят по stop
+			select {
+			case signal <- struct{}{}:
+			default:
+			}
+		}()
+	}
+
+	go func() {
+		<-signal
+		close(stop)
+		close(out)
+	}()
+
+	return out
+}
+
+// And - канал, который закрывается, когда каждый из channels прислал значение или закрылся
+func And[T any](channels ...<-chan T) <-chan T {
+	return AndContext(context.Background(), channels...)
+}
+
+// AndContext - And, который закрывается и при отмене ctx
+func AndContext[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	out := make(chan T)
+	done := ctx.Done()
+
+	// каналы можно ждать по очереди: закрытый канал читается сразу, а отправитель
+	// значения просто подождет, пока до его канала дойдет очередь
+	go func() {
+		defer close(out)
+
+		for _, ch := range channels {
+			select {
+			case <-ch:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,183 @@
+package chanutil
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge - fan-in: все значения из channels в одном канале, который закрывается,
+// когда закрылись все входные. Порядок значений разных каналов не сохраняется
+func Merge[T any](channels ...<-chan T) <-chan T {
+	return MergeContext(context.Background(), channels...)
+}
+
+// MergeContext - Merge, который при отмене ctx перестает читать входы и закрывается
+func MergeContext[T any](ctx context.Context, channels ...<-chan T) <-chan T {
+	out := make(chan T)
+	done := ctx.Done()
+
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		wg.Go(func() {
+			forward(done, ch, out)
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// Tee - каждое значение in попадает в оба выходных канала. Следующее значение читается,
+// только когда текущее забрали оба читателя
+func Tee[T any](in <-chan T) (<-chan T, <-chan T) {
+	return TeeContext(context.Background(), in)
+}
+
+// TeeContext - Tee, который при отмене ctx закрывает оба канала, даже если читатели ушли
+func TeeContext[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
+	out1, out2 := make(chan T), make(chan T)
+	done := ctx.Done()
+
+	go func() {
+		defer close(out1)
+		defer close(out2)
+
+		for {
+			var v T
+			var ok bool
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-done:
+				return
+			}
+
+			// отправленному каналу присваиваем nil, чтобы второй select ждал только другой
+			o1, o2 := out1, out2
+			for range 2 {
+				select {
+				case o1 <- v:
+					o1 = nil
+				case o2 <- v:
+					o2 = nil
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return out1, out2
+}
+
+// Bridge - значения из последовательности каналов подряд: сначала весь первый канал, потом второй...
+// Результат закрывается, когда закрылся chanStream и последний из его каналов
+func Bridge[T any](chanStream <-chan <-chan T) <-chan T {
+	return BridgeContext(context.Background(), chanStream)
+}
+
+// BridgeContext - Bridge, который при отмене ctx перестает читать и закрывается
+func BridgeContext[T any](ctx context.Context, chanStream <-chan <-chan T) <-chan T {
+	out := make(chan T)
+	done := ctx.Done()
+
+	go func() {
+		defer close(out)
+
+		for {
+			var stream <-chan T
+			select {
+			case s, ok := <-chanStream:
+				if !ok {
+					return
+				}
+				stream = s
+			case <-done:
+				return
+			}
+
+			if !forward(done, stream, out) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// OrDone - значения in, пока не закрыт done: чтение из канала можно прервать снаружи,
+// не дожидаясь его закрытия
+func OrDone[T any](done <-chan struct{}, in <-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+		forward(done, in, out)
+	}()
+
+	return out
+}
+
+// OrDoneContext - OrDone с отменой через ctx
+func OrDoneContext[T any](ctx context.Context, in <-chan T) <-chan T {
+	return OrDone(ctx.Done(), in)
+}
+
+// Take - первые n значений in, после чего результат закрывается. Остаток in не читается
+func Take[T any](in <-chan T, n int) <-chan T {
+	return TakeContext(context.Background(), in, n)
+}
+
+// TakeContext - Take, который при отмене ctx закрывается раньше
+func TakeContext[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	out := make(chan T)
+	done := ctx.Done()
+
+	go func() {
+		defer close(out)
+
+		for range n {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- v:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// forward - перекладываем значения из in в out, пока in не закрыт.
+// false - остановились по done
+func forward[T any](done <-chan struct{}, in <-chan T, out chan<- T) bool {
+	for {
+		select {
+		case v, ok := <-in:
+			if !ok {
+				return true
+			}
+			select {
+			case out <- v:
+			case <-done:
+				return false
+			}
+		case <-done:
+			return false
+		}
+	}
+}
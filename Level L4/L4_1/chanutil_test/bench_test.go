@@ -0,0 +1,170 @@
+package chanutil_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"L4.1/chanutil"
+)
+
+// legacyOr - прежняя реализация or.Or для сравнения. Она закрывает результат сразу после
+// запуска горутин, не дожидаясь сигнала, а горутины на несработавших каналах остаются навсегда,
+// поэтому в бенчмарке все входы закрыты заранее - иначе каждая итерация оставляла бы утечку.
+// Сигнал, пришедший после закрытия chDone, роняет исходный код с "send on closed channel",
+// поэтому единственное отличие копии - recover в горутине
+func legacyOr(channels ...<-chan interface{}) <-chan interface{} {
+	chDone := make(chan interface{})
+
+	go func() {
+		defer close(chDone)
+
+		for _, ch := range channels {
+			go func(c <-chan interface{}) {
+				defer func() { _ = recover() }()
+				<-c
+				select {
+				case chDone <- struct{}{}:
+				default:
+				}
+			}(ch)
+		}
+	}()
+
+	return chDone
+}
+
+var sizes = []int{1, 4, 16, 128, 1024}
+
+// closedInputs - n уже закрытых каналов: сигнал есть сразу, меряется только цена самого Or
+func closedInputs(n int) []<-chan interface{} {
+	channels := make([]<-chan interface{}, n)
+	for i := range channels {
+		ch := make(chan interface{})
+		close(ch)
+		channels[i] = ch
+	}
+	return channels
+}
+
+func BenchmarkOr(b *testing.B) {
+	for _, n := range sizes {
+		channels := closedInputs(n)
+
+		b.Run(fmt.Sprintf("chanutil/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				<-chanutil.Or(channels...)
+			}
+		})
+
+		b.Run(fmt.Sprintf("legacy/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				<-legacyOr(channels...)
+			}
+		})
+	}
+}
+
+// BenchmarkOrLastSignal - сигнал приходит только из последнего канала, остальные молчат:
+// здесь видно, что после сигнала горутины на молчащих каналах завершаются
+func BenchmarkOrLastSignal(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			channels := make([]<-chan interface{}, n)
+			for i := range channels {
+				channels[i] = make(chan interface{})
+			}
+
+			for b.Loop() {
+				last := make(chan interface{})
+				channels[n-1] = last
+				out := chanutil.Or(channels...)
+				close(last)
+				<-out
+			}
+		})
+	}
+}
+
+func BenchmarkAnd(b *testing.B) {
+	for _, n := range sizes {
+		channels := closedInputs(n)
+
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for b.Loop() {
+				<-chanutil.And(channels...)
+			}
+		})
+	}
+}
+
+func BenchmarkMerge(b *testing.B) {
+	const values = 1024
+
+	for _, n := range []int{1, 4, 16} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for b.Loop() {
+				inputs := make([]<-chan int, n)
+				for i := range inputs {
+					inputs[i] = generate(context.Background(), values/n)
+				}
+				for range chanutil.Merge(inputs...) {
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTee(b *testing.B) {
+	for b.Loop() {
+		out1, out2 := chanutil.Tee(generate(context.Background(), 1024))
+		go func() {
+			for range out2 {
+			}
+		}()
+		for range out1 {
+		}
+	}
+}
+
+func BenchmarkBridge(b *testing.B) {
+	for b.Loop() {
+		streams := make(chan (<-chan int))
+		go func() {
+			defer close(streams)
+			for range 16 {
+				streams <- generate(context.Background(), 64)
+			}
+		}()
+		for range chanutil.Bridge(streams) {
+		}
+	}
+}
+
+func BenchmarkOrDone(b *testing.B) {
+	done := make(chan struct{})
+
+	for b.Loop() {
+		for range chanutil.OrDone(done, generate(context.Background(), 1024)) {
+		}
+	}
+}
+
+func BenchmarkTake(b *testing.B) {
+	for b.Loop() {
+		ctx, cancel := context.WithCancel(context.Background())
+		for range chanutil.TakeContext(ctx, generate(ctx, 2048), 1024) {
+		}
+		// generate остановится по отмене, не дописав вторую половину
+		cancel()
+	}
+}
+
+// generate - канал с числами 0..n-1
+func generate(ctx context.Context, n int) <-chan int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i
+	}
+	return produce(ctx, values...)
+}
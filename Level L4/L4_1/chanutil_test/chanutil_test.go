@@ -0,0 +1,349 @@
+package chanutil_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"L4.1/chanutil"
+)
+
+// Все тесты идут внутри synctest.Test: он ждет завершения всех горутин пузыря и падает,
+// если какие-то из них остались заблокированными, то есть утекли
+
+// sig - канал, который закрывается через after (время в пузыре synctest ненастоящее)
+// или при отмене ctx, чтобы сама горутина теста тоже не осталась висеть
+func sig(ctx context.Context, after time.Duration) <-chan any {
+	ch := make(chan any)
+	go func() {
+		defer close(ch)
+		select {
+		case <-time.After(after):
+		case <-ctx.Done():
+		}
+	}()
+	return ch
+}
+
+// closed - канал закрыт, не блокируясь
+func closed[T any](ch <-chan T) bool {
+	select {
+	case _, ok := <-ch:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// produce - отправляет values, пока их читают или пока не отменен ctx
+func produce[T any](ctx context.Context, values ...T) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for _, v := range values {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func collect[T any](ch <-chan T) []T {
+	var values []T
+	for v := range ch {
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestOr(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx := t.Context()
+		start := time.Now()
+
+		<-chanutil.Or(
+			sig(ctx, 2*time.Hour),
+			sig(ctx, 5*time.Minute),
+			sig(ctx, 1*time.Second),
+			sig(ctx, 2*time.Second),
+		)
+
+		if elapsed := time.Since(start); elapsed != time.Second {
+			t.Fatalf("Or closed after %v, want 1s", elapsed)
+		}
+	})
+}
+
+func TestOrManyChannels(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		channels := make([]chan int, 101)
+		inputs := make([]<-chan int, len(channels))
+		for i := range channels {
+			channels[i] = make(chan int)
+			inputs[i] = channels[i]
+		}
+
+		out := chanutil.Or(inputs...)
+
+		synctest.Wait()
+		if closed(out) {
+			t.Fatal("Or closed before any signal")
+		}
+
+		// значение, а не закрытие, тоже сигнал
+		channels[57] <- 1
+
+		synctest.Wait()
+		if !closed(out) {
+			t.Fatal("Or is not closed after a value")
+		}
+		// остальные каналы не закрыты, но горутины Or уже вышли - иначе synctest.Test упадет
+	})
+}
+
+func TestOrContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+
+		never := make(chan struct{})
+		out := chanutil.OrContext(ctx, never, never, never, never, never)
+
+		synctest.Wait()
+		if closed(out) {
+			t.Fatal("OrContext closed before cancel")
+		}
+
+		cancel()
+		synctest.Wait()
+		if !closed(out) {
+			t.Fatal("OrContext is not closed after cancel")
+		}
+	})
+}
+
+func TestOrNoChannels(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		out := chanutil.OrContext[int](ctx)
+
+		synctest.Wait()
+		if closed(out) {
+			t.Fatal("OrContext without channels closed before cancel")
+		}
+
+		cancel()
+		synctest.Wait()
+		if !closed(out) {
+			t.Fatal("OrContext without channels is not closed after cancel")
+		}
+	})
+}
+
+func TestAnd(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx := t.Context()
+		start := time.Now()
+
+		<-chanutil.And(sig(ctx, 3*time.Second), sig(ctx, time.Second), sig(ctx, 2*time.Second))
+
+		if elapsed := time.Since(start); elapsed != 3*time.Second {
+			t.Fatalf("And closed after %v, want 3s", elapsed)
+		}
+	})
+}
+
+func TestAndValues(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		a, b := make(chan int), make(chan int)
+		out := chanutil.And(a, b)
+
+		// And ждет каналы по очереди, поэтому отправитель b подождет, пока дойдет до него
+		go func() { b <- 1 }()
+		synctest.Wait()
+		if closed(out) {
+			t.Fatal("And closed after one of two signals")
+		}
+
+		close(a)
+		synctest.Wait()
+		if !closed(out) {
+			t.Fatal("And is not closed after all signals")
+		}
+	})
+}
+
+func TestAndContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		out := chanutil.AndContext(ctx, sig(ctx, time.Second), make(chan any))
+
+		time.Sleep(time.Hour)
+		if closed(out) {
+			t.Fatal("AndContext closed without all signals")
+		}
+
+		cancel()
+		synctest.Wait()
+		if !closed(out) {
+			t.Fatal("AndContext is not closed after cancel")
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx := t.Context()
+		got := collect(chanutil.Merge(produce(ctx, 1, 2, 3), produce(ctx, 4, 5), produce[int](ctx)))
+
+		slices.Sort(got)
+		if want := []int{1, 2, 3, 4, 5}; !slices.Equal(got, want) {
+			t.Fatalf("Merge = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestMergeContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		out := chanutil.MergeContext(ctx, produce(ctx, 1, 2, 3), produce(ctx, 4, 5, 6))
+
+		// читатель забрал одно значение и ушел: отправители Merge не должны повиснуть
+		<-out
+		cancel()
+
+		synctest.Wait()
+		for range out {
+		}
+	})
+}
+
+func TestTee(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		out1, out2 := chanutil.Tee(produce(t.Context(), 1, 2, 3))
+
+		var got1, got2 []int
+		for range 3 {
+			// читаем в разном порядке: Tee не должен зависеть от того, кто читает первым
+			got2 = append(got2, <-out2)
+			got1 = append(got1, <-out1)
+		}
+
+		want := []int{1, 2, 3}
+		if !slices.Equal(got1, want) || !slices.Equal(got2, want) {
+			t.Fatalf("Tee = %v, %v, want %v", got1, got2, want)
+		}
+
+		synctest.Wait()
+		if !closed(out1) || !closed(out2) {
+			t.Fatal("Tee outputs are not closed after input")
+		}
+	})
+}
+
+func TestTeeContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		out1, out2 := chanutil.TeeContext(ctx, produce(ctx, 1, 2, 3))
+
+		// второй читатель не читает совсем, Tee ждет его до отмены
+		<-out1
+		cancel()
+
+		synctest.Wait()
+		for range out1 {
+		}
+		for range out2 {
+		}
+	})
+}
+
+func TestBridge(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx := t.Context()
+		streams := make(chan (<-chan int))
+		go func() {
+			defer close(streams)
+			streams <- produce(ctx, 1, 2)
+			streams <- produce[int](ctx)
+			streams <- produce(ctx, 3)
+		}()
+
+		got := collect(chanutil.Bridge(streams))
+		if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+			t.Fatalf("Bridge = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestBridgeContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		streams := make(chan (<-chan int), 1)
+		streams <- produce(ctx, 1, 2, 3)
+
+		out := chanutil.BridgeContext(ctx, streams)
+		<-out
+		cancel()
+
+		synctest.Wait()
+		for range out {
+		}
+	})
+}
+
+func TestOrDone(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		done := make(chan struct{})
+		in := make(chan int)
+		out := chanutil.OrDone(done, in)
+
+		in <- 1
+		if v := <-out; v != 1 {
+			t.Fatalf("OrDone = %d, want 1", v)
+		}
+
+		// in так и не закрыт, но done прерывает чтение
+		close(done)
+		synctest.Wait()
+		if !closed(out) {
+			t.Fatal("OrDone is not closed after done")
+		}
+	})
+}
+
+func TestTake(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		got := collect(chanutil.Take(produce(ctx, 1, 2, 3, 4, 5), 3))
+		if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+			t.Fatalf("Take = %v, want %v", got, want)
+		}
+
+		// входной канал короче n
+		got = collect(chanutil.Take(produce(ctx, 1), 3))
+		if want := []int{1}; !slices.Equal(got, want) {
+			t.Fatalf("Take = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestTakeContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		out := chanutil.TakeContext(ctx, produce(ctx, 1, 2, 3), 3)
+
+		<-out
+		cancel()
+
+		synctest.Wait()
+		for range out {
+		}
+	})
+}
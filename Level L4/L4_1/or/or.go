@@ -1,22 +1,9 @@
 package or
 
-// Or - объединение каналов в один
-func Or(channels ...<-chan interface{}) <-chan interface{} {
-	chDone := make(chan interface{})
-
-	go func() {
-		defer close(chDone)
+import "L4.1/chanutil"
 
-		for _, ch := range channels {
-			go func(c <-chan interface{}) {
-				<-c
-				select {
-				case chDone <- struct{}{}:
-				default:
-				}
-			}(ch)
-		}
-	}()
-
-	return chDone
+// Or - объединение каналов в один: результат закрывается, как только любой из каналов
+// прислал значение или закрылся. Реализация в chanutil.Or, горутины после сигнала не остаются
+func Or(channels ...<-chan interface{}) <-chan interface{} {
+	return chanutil.Or(channels...)
 }